@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/server"
+	"github.com/krlosmederos/locqube-challenge/pkg/source"
+)
+
+func main() {
+	fs := flag.NewFlagSet("valuation-server", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	configPath := fs.String("config", config.DefaultPath, "path to the configuration file")
+	dataPath := fs.String("data", "data/market_listings_response.json", "path or URL of the default market listings, empty to require listings in requests")
+	config.RegisterFlags(fs)
+	fs.Parse(os.Args[1:])
+
+	cfg, _, err := config.LoadLayered(*configPath, config.FlagOverrides(fs))
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	sources := map[string][]models.Property{}
+	if *dataPath != "" {
+		listings, err := readListings(*dataPath)
+		if err != nil {
+			log.Fatalf("Error reading market listings: %v", err)
+		}
+		sources[server.DefaultSource] = listings
+	}
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           server.NewServer(cfg, sources),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.Printf("Listening on %s", *addr)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Error starting server: %v", err)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		log.Fatalf("Error shutting down server: %v", err)
+	}
+}
+
+func readListings(location string) ([]models.Property, error) {
+	src, err := source.Open(location)
+	if err != nil {
+		return nil, err
+	}
+	return source.All(src)
+}
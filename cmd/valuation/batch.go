@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/batch"
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+)
+
+// runBatch values every subject of a CSV or JSONL file against the market
+// data and streams one result per subject
+func runBatch(args []string) {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	configPath := fs.String("config", config.DefaultPath, "path to the configuration file")
+	dataPath := fs.String("data", listingsPath, "path or URL of the market listings (file, directory, .jsonl, .csv or http)")
+	subjectsPath := fs.String("subjects", "", "path to the subjects file (.csv or .jsonl)")
+	outPath := fs.String("out", "", "path to write the results, stdout when empty")
+	format := fs.String("format", "jsonl", "output format: jsonl or csv")
+	workers := fs.Int("workers", 0, "number of concurrent valuations, the number of CPUs when 0")
+	config.RegisterFlags(fs)
+	fs.Parse(args)
+
+	if *subjectsPath == "" {
+		log.Fatal("Error: -subjects is required")
+	}
+
+	cfg, _, err := config.LoadLayered(*configPath, config.FlagOverrides(fs))
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	listings, err := readListings(*dataPath)
+	if err != nil {
+		log.Fatalf("Error reading market listings: %v", err)
+	}
+
+	subjectsFile, err := os.Open(*subjectsPath)
+	if err != nil {
+		log.Fatalf("Error opening subjects: %v", err)
+	}
+	defer subjectsFile.Close()
+
+	read := batch.ReadJSONL
+	if strings.EqualFold(filepath.Ext(*subjectsPath), ".csv") {
+		read = batch.ReadCSV
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatalf("Error creating output file: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	var writer batch.Writer
+	switch *format {
+	case "jsonl":
+		writer = batch.NewJSONLWriter(out)
+	case "csv":
+		writer = batch.NewCSVWriter(out)
+	default:
+		log.Fatalf("Error: unknown output format %q", *format)
+	}
+
+	subjects := make(chan batch.Subject)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- read(subjectsFile, subjects)
+	}()
+
+	runner := batch.NewRunner(cfg, listings, *workers)
+	summary, err := runner.Run(subjects, writer.Write)
+	if err != nil {
+		log.Fatalf("Error writing results: %v", err)
+	}
+	if err := writer.Flush(); err != nil {
+		log.Fatalf("Error writing results: %v", err)
+	}
+	if err := <-readErr; err != nil {
+		log.Fatalf("Error reading subjects: %v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Subjects: %d  valued: %d  no comparables: %d  failed: %d  duration: %s\n",
+		summary.Total, summary.Valued, summary.NoComparables, summary.Failed, summary.Duration)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+)
+
+// runConfig handles the config subcommands
+func runConfig(args []string) {
+	if len(args) == 0 || args[0] != "show" {
+		fmt.Fprintln(os.Stderr, "usage: valuation config show [flags]")
+		os.Exit(2)
+	}
+
+	runConfigShow(args[1:])
+}
+
+// runConfigShow prints the effective configuration and the layer every value came from
+func runConfigShow(args []string) {
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	configPath := fs.String("config", config.DefaultPath, "path to the configuration file, empty to skip the file layer")
+	profile := fs.String("profile", config.DefaultProfile, "configuration profile to show")
+	config.RegisterFlags(fs)
+	fs.Parse(args)
+
+	cfg, sources, err := config.LoadLayered(*configPath, config.FlagOverrides(fs))
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	if *profile != config.DefaultProfile {
+		sources = cfg.ProfileSources(*profile, sources)
+		cfg, err = cfg.Profile(*profile)
+		if err != nil {
+			log.Fatalf("Error selecting configuration profile: %v", err)
+		}
+	}
+
+	for _, line := range config.Describe(cfg, sources) {
+		fmt.Println(line)
+	}
+}
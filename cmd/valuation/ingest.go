@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/repository"
+	"github.com/krlosmederos/locqube-challenge/pkg/source"
+)
+
+// defaultRepositoryPath is where ingested listings are accumulated
+const defaultRepositoryPath = "data/listings.db"
+
+// runIngest upserts a listings snapshot into the repository
+func runIngest(args []string) {
+	fs := flag.NewFlagSet("ingest", flag.ExitOnError)
+	dbPath := fs.String("db", defaultRepositoryPath, "path to the listings repository")
+	dataPath := fs.String("data", listingsPath, "path or URL of the market listings to ingest")
+	csvMapping := fs.String("csv-mapping", "", "path to a JSON file mapping the CSV headers of -data to listing fields")
+	compact := fs.Bool("compact", false, "reclaim the space of replaced listing versions in the repository file")
+	fs.Parse(args)
+
+	src, err := openListings(*dataPath, *csvMapping)
+	if err != nil {
+		log.Fatalf("Error opening market listings: %v", err)
+	}
+	listings, err := source.All(src)
+	if err != nil {
+		log.Fatalf("Error reading market listings: %v", err)
+	}
+
+	repo, err := repository.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("Error opening repository: %v", err)
+	}
+	defer repo.Close()
+
+	stats, err := repo.Ingest(listings)
+	if err != nil {
+		log.Fatalf("Error ingesting listings: %v", err)
+	}
+
+	if *compact {
+		if err := repo.Compact(); err != nil {
+			log.Fatalf("Error compacting repository: %v", err)
+		}
+	}
+
+	fmt.Printf("Inserted: %d  updated: %d  unchanged: %d  invalid: %d  total listings: %d\n",
+		stats.Inserted, stats.Updated, stats.Unchanged, stats.Invalid, repo.Len())
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/source"
+)
+
+// lintResult holds the issues of one listing
+type lintResult struct {
+	Index  int            `json:"index"`
+	ID     string         `json:"id"`
+	Issues []models.Issue `json:"issues"`
+}
+
+// runLint reports the data quality issues of every listing and exits with
+// status 1 when any listing has an error
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	dataPath := fs.String("data", listingsPath, "path or URL of the market listings (file, directory, .jsonl, .csv or http)")
+	csvMapping := fs.String("csv-mapping", "", "path to a JSON file mapping the CSV headers of -data to listing fields")
+	format := fs.String("format", "text", "output format: text or json")
+	errorsOnly := fs.Bool("errors", false, "report errors only")
+	fs.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		log.Fatalf("Error: unknown output format %q", *format)
+	}
+
+	src, err := openListings(*dataPath, *csvMapping)
+	if err != nil {
+		log.Fatalf("Error opening market listings: %v", err)
+	}
+	listings, err := source.All(src)
+	if err != nil {
+		log.Fatalf("Error reading market listings: %v", err)
+	}
+
+	var results []lintResult
+	rules := map[string]int{}
+	withErrors, withWarnings := 0, 0
+	for i, p := range listings {
+		var issues []models.Issue
+		for _, issue := range p.Validate() {
+			if *errorsOnly && issue.Severity != models.SeverityError {
+				continue
+			}
+			issues = append(issues, issue)
+			rules[issue.Severity+" "+issue.Rule]++
+		}
+		if len(issues) == 0 {
+			continue
+		}
+
+		if models.HasErrors(issues) {
+			withErrors++
+		} else {
+			withWarnings++
+		}
+		results = append(results, lintResult{Index: i, ID: p.ID, Issues: issues})
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, r := range results {
+			if err := encoder.Encode(r); err != nil {
+				log.Fatalf("Error writing report: %v", err)
+			}
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "INDEX\tID\tSEVERITY\tFIELD\tRULE\tMESSAGE")
+		for _, r := range results {
+			for _, issue := range r.Issues {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", r.Index, r.ID, issue.Severity, issue.Field, issue.Rule, issue.Message)
+			}
+		}
+		w.Flush()
+	}
+
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(os.Stderr, "Listings: %d  with errors: %d  with warnings only: %d\n", len(listings), withErrors, withWarnings)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-40s %d\n", name, rules[name])
+	}
+
+	if withErrors > 0 {
+		os.Exit(1)
+	}
+}
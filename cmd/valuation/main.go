@@ -1,21 +1,86 @@
 package main
 
 import (
-	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"time"
 
 	"github.com/krlosmederos/locqube-challenge/pkg/algorithm"
 	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/export"
 	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/repository"
+	"github.com/krlosmederos/locqube-challenge/pkg/source"
 )
 
+const listingsPath = "data/market_listings_response.json"
+
 func main() {
-	if _, err := config.LoadConfig(); err != nil {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "tune":
+			runTune(os.Args[2:])
+			return
+		case "batch":
+			runBatch(os.Args[2:])
+			return
+		case "config":
+			runConfig(os.Args[2:])
+			return
+		case "ingest":
+			runIngest(os.Args[2:])
+			return
+		case "lint":
+			runLint(os.Args[2:])
+			return
+		case "scan":
+			runScan(os.Args[2:])
+			return
+		case "market-stats":
+			runMarketStats(os.Args[2:])
+			return
+		}
+	}
+
+	runValuation(os.Args[1:])
+}
+
+func runValuation(args []string) {
+	fs := flag.NewFlagSet("valuation", flag.ExitOnError)
+	configPath := fs.String("config", config.DefaultPath, "path to the configuration file")
+	dataPath := fs.String("data", listingsPath, "path or URL of the market listings (file, directory, .jsonl, .csv or http)")
+	dbPath := fs.String("db", "", "path to a listings repository to pull comparables from instead of -data")
+	csvMapping := fs.String("csv-mapping", "", "path to a JSON file mapping the CSV headers of -data to listing fields")
+	profile := fs.String("profile", "", "configuration profile to use instead of the one matching the subject's address")
+	outPath := fs.String("out", "", "path to write the estimate as CSV")
+	gridPath := fs.String("grid", "", "path to write the comp grid as CSV")
+	subjectID := fs.String("subject-id", "", "ID or system ID of a listing of the source to value instead of the sample subject")
+	rent := fs.Bool("rent", false, "also estimate the monthly rent from the lease listings and the rental yield")
+	rentalProfile := fs.String("rental-profile", "", "configuration profile of the rent estimate instead of the rental one")
+	asOf := fs.String("as-of", "", "date to value the subject at as YYYY-MM-DD, today when empty")
+	config.RegisterFlags(fs)
+	fs.Parse(args)
+
+	cfg, _, err := config.LoadLayered(*configPath, config.FlagOverrides(fs))
+	if err != nil {
 		log.Fatalf("Error loading configuration: %v", err)
 	}
 
+	var valuedAt time.Time
+	if *asOf != "" {
+		date, err := time.Parse("2006-01-02", *asOf)
+		if err != nil {
+			log.Fatalf("Error: invalid -as-of date %q", *asOf)
+		}
+		valuedAt = date.Add(24*time.Hour - time.Second)
+	}
+
+	src, closeSource := openSource(*dbPath, *dataPath, *csvMapping)
+	defer closeSource()
+
 	subject := models.Property{
 		PropertyType: "Single-family home",
 		Beds:         4,
@@ -31,18 +96,135 @@ func main() {
 		},
 	}
 
-	data, err := os.ReadFile("data/market_listings_response.json")
+	if *subjectID != "" {
+		listing, ok, err := source.Find(src, *subjectID)
+		if err != nil {
+			log.Fatalf("Error reading market listings: %v", err)
+		}
+		if !ok {
+			log.Fatalf("Error: no listing with ID %s", *subjectID)
+		}
+		subject = listing
+	}
+
+	valuation, err := algorithm.NewValuationWithSource(subject, src, cfg)
 	if err != nil {
 		log.Fatalf("Error reading market listings: %v", err)
 	}
 
-	var listings []models.Property
-	if err := json.Unmarshal(data, &listings); err != nil {
-		log.Fatalf("Error parsing market listings: %v", err)
+	if *profile != "" {
+		valuation, err = algorithm.NewValuationWithProfile(subject, valuation.Listings, cfg, *profile)
+		if err != nil {
+			log.Fatalf("Error selecting configuration profile: %v", err)
+		}
+		if valuation.Config.ValuationType == config.ValuationRent {
+			log.Fatalf("Error: profile %s values rents, select it with -rental-profile", *profile)
+		}
+	}
+	valuation.AsOf = valuedAt
+	result := valuation.Estimate()
+
+	if *subjectID != "" {
+		fmt.Printf("Subject: %s, %s, %s\n", subject.ID, subject.Address.Street, subject.Address.City)
 	}
+	fmt.Printf("Estimated Property Value: $%.2f\n", result.Value)
+	fmt.Printf("Configuration Profile: %s\n", result.Profile)
+	if result.SaleToListRatio > 0 {
+		fmt.Printf("Sale-to-List Ratio: %.3f\n", result.SaleToListRatio)
+	}
+
+	// a lease subject is priced in monthly rent so its rent is always estimated
+	if *rent || subject.IsLease() {
+		rental, err := algorithm.NewRentalValuation(subject, valuation.Listings, cfg, *rentalProfile)
+		if err != nil {
+			log.Fatalf("Error selecting configuration profile: %v", err)
+		}
+		rental.AsOf = valuedAt
+		rentResult := rental.Estimate()
 
-	valuation := algorithm.NewValuation(subject, listings)
-	estimatedValue := valuation.Calculate()
+		fmt.Printf("Estimated Monthly Rent: $%.2f\n", rentResult.Value)
+		fmt.Printf("Rental Configuration Profile: %s\n", rentResult.Profile)
+		if rentResult.Value > 0 && result.Value > 0 {
+			yield := algorithm.RentalYield(rentResult.Value, result.Value, rental.Config.Rental.ExpenseRatio)
+			fmt.Printf("Gross Yield: %.2f%%\n", yield.GrossYield*100)
+			fmt.Printf("Cap Rate: %.2f%%\n", yield.CapRate*100)
+		}
 
-	fmt.Printf("Estimated Property Value: $%.2f\n", estimatedValue)
+		// the list price and the exports of a lease subject are its rent
+		if subject.IsLease() {
+			result = rentResult
+		}
+	}
+
+	if *subjectID != "" && subject.ListPrice > 0 {
+		diff, ratio := result.PriceGap(subject.ListPrice)
+		fmt.Printf("List Price: $%.2f\n", subject.ListPrice)
+		if result.Value > 0 {
+			fmt.Printf("List Price vs Estimate: %+.2f (%+.1f%%)\n", diff, ratio*100)
+		}
+	}
+
+	if *outPath != "" {
+		if err := writeFile(*outPath, func(w io.Writer) error { return export.WriteResultCSV(w, subject, result) }); err != nil {
+			log.Fatalf("Error writing estimate: %v", err)
+		}
+	}
+	if *gridPath != "" {
+		if err := writeFile(*gridPath, func(w io.Writer) error { return export.WriteCompGridCSV(w, subject, result) }); err != nil {
+			log.Fatalf("Error writing comp grid: %v", err)
+		}
+	}
+}
+
+// openSource opens the listings repository when a path is given, the market
+// listings otherwise, and returns a function closing it
+func openSource(dbPath, dataPath, csvMapping string) (source.ListingSource, func()) {
+	if dbPath != "" {
+		repo, err := repository.Open(dbPath)
+		if err != nil {
+			log.Fatalf("Error opening repository: %v", err)
+		}
+		return repo, func() { repo.Close() }
+	}
+
+	src, err := openListings(dataPath, csvMapping)
+	if err != nil {
+		log.Fatalf("Error opening market listings: %v", err)
+	}
+	return src, func() {}
+}
+
+// openListings opens the listing source of a path or URL, a CSV file is read
+// with the mapping file when one is given
+func openListings(location, csvMapping string) (source.ListingSource, error) {
+	if csvMapping == "" {
+		return source.Open(location)
+	}
+
+	mapping, err := source.LoadCSVMapping(csvMapping)
+	if err != nil {
+		return nil, err
+	}
+	return &source.CSV{Path: location, Mapping: mapping}, nil
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// readListings reads every listing from a path or URL, see source.Open
+func readListings(location string) ([]models.Property, error) {
+	src, err := source.Open(location)
+	if err != nil {
+		return nil, err
+	}
+	return source.All(src)
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/market"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+// runMarketStats reports the market statistics of the listings grouped by
+// city, zip code or property type
+func runMarketStats(args []string) {
+	fs := flag.NewFlagSet("market-stats", flag.ExitOnError)
+	configPath := fs.String("config", config.DefaultPath, "path to the configuration file, its designated cities group the city names")
+	dataPath := fs.String("data", listingsPath, "path or URL of the market listings (file, directory, .jsonl, .csv or http)")
+	dbPath := fs.String("db", "", "path to a listings repository to read instead of -data")
+	csvMapping := fs.String("csv-mapping", "", "path to a JSON file mapping the CSV headers of -data to listing fields")
+	city := fs.String("city", "", "only the listings of this city")
+	zip := fs.String("zip", "", "only the listings of this zip code")
+	groupBy := fs.String("group-by", "city", "grouping: city, zip, property_type or none")
+	months := fs.Int("months", 6, "number of calendar months of sales, ending with -as-of")
+	asOf := fs.String("as-of", "", "end date of the period as YYYY-MM-DD, today when empty")
+	format := fs.String("format", "text", "output format: text or json")
+	rentals := fs.Bool("rent", false, "report the lease listings and their monthly rents instead of the sales")
+	fs.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		log.Fatalf("Error: unknown output format %q", *format)
+	}
+
+	opts := market.Options{GroupBy: *groupBy, Months: *months, Rentals: *rentals}
+	switch *groupBy {
+	case market.GroupByCity, market.GroupByZip, market.GroupByPropertyType:
+	case "none":
+		opts.GroupBy = market.GroupByNone
+	default:
+		log.Fatalf("Error: unknown grouping %q", *groupBy)
+	}
+	if *asOf != "" {
+		date, err := time.Parse("2006-01-02", *asOf)
+		if err != nil {
+			log.Fatalf("Error: invalid -as-of date %q", *asOf)
+		}
+		// the period includes the whole end date
+		opts.AsOf = date.Add(24*time.Hour - time.Second)
+	}
+
+	if _, _, err := config.LoadLayered(*configPath, nil); err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	src, closeSource := openSource(*dbPath, *dataPath, *csvMapping)
+	defer closeSource()
+
+	listings, err := src.Listings(store.Query{City: *city, Zip: *zip})
+	if err != nil {
+		log.Fatalf("Error reading market listings: %v", err)
+	}
+
+	stats := market.Compute(listings, opts)
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, s := range stats {
+			if err := encoder.Encode(s); err != nil {
+				log.Fatalf("Error writing report: %v", err)
+			}
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tACTIVE\tPENDING\tSALES\tMEDIAN PRICE\tMEDIAN $/SQFT\tSALE/LIST\tAVG DOM\tSALES/MONTH\tMONTHS OF INVENTORY")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.0f\t%.2f\t%.3f\t%.1f\t%.2f\t%.1f\n",
+			s.Group, s.Active, s.Pending, s.Sales, s.MedianSalePrice, s.MedianPricePerSqft,
+			s.SaleToListRatio, s.AverageDaysOnMarket, s.AbsorptionRate, s.MonthsOfInventory)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "GROUP\tMONTH\tSALES\tMEDIAN PRICE\tMEDIAN $/SQFT\tSALE/LIST")
+	for _, s := range stats {
+		for _, p := range s.Trend {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%.0f\t%.2f\t%.3f\n",
+				s.Group, p.Month, p.Sales, p.MedianSalePrice, p.MedianPricePerSqft, p.SaleToListRatio)
+		}
+	}
+	w.Flush()
+}
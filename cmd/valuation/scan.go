@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/scan"
+	"github.com/krlosmederos/locqube-challenge/pkg/source"
+)
+
+// runScan values every active listing against the closed sales and lists
+// them by the gap between their list price and the estimate
+func runScan(args []string) {
+	fs := flag.NewFlagSet("scan", flag.ExitOnError)
+	configPath := fs.String("config", config.DefaultPath, "path to the configuration file")
+	dataPath := fs.String("data", listingsPath, "path or URL of the market listings (file, directory, .jsonl, .csv or http)")
+	dbPath := fs.String("db", "", "path to a listings repository to scan instead of -data")
+	csvMapping := fs.String("csv-mapping", "", "path to a JSON file mapping the CSV headers of -data to listing fields")
+	format := fs.String("format", "text", "output format: text or json")
+	minConfidence := fs.String("min-confidence", "low", "lowest confidence listed: low, medium or high")
+	limit := fs.Int("limit", 0, "number of listings to list, all when 0")
+	workers := fs.Int("workers", 0, "number of concurrent valuations, the number of CPUs when 0")
+	config.RegisterFlags(fs)
+	fs.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		log.Fatalf("Error: unknown output format %q", *format)
+	}
+	min := scan.Confidence(*minConfidence)
+	if min != scan.ConfidenceLow && min != scan.ConfidenceMedium && min != scan.ConfidenceHigh {
+		log.Fatalf("Error: unknown confidence %q", *minConfidence)
+	}
+
+	cfg, _, err := config.LoadLayered(*configPath, config.FlagOverrides(fs))
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	src, closeSource := openSource(*dbPath, *dataPath, *csvMapping)
+	defer closeSource()
+
+	listings, err := source.All(src)
+	if err != nil {
+		log.Fatalf("Error reading market listings: %v", err)
+	}
+
+	ranked, summary, err := scan.Run(cfg, listings, *workers)
+	if err != nil {
+		log.Fatalf("Error valuing listings: %v", err)
+	}
+
+	var results []scan.Listing
+	for _, l := range ranked {
+		if l.Confidence.AtLeast(min) {
+			results = append(results, l)
+		}
+	}
+	if *limit > 0 && len(results) > *limit {
+		results = results[:*limit]
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, r := range results {
+			if err := encoder.Encode(r); err != nil {
+				log.Fatalf("Error writing results: %v", err)
+			}
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSTREET\tCITY\tLIST PRICE\tESTIMATE\tRANGE\tRECOMMENDED\tGAP\tPOSITION\tCOMPS\tCONFIDENCE")
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%.0f\t%.0f\t%.0f-%.0f\t%.0f\t%+.1f%%\t%s\t%d\t%s\n",
+				r.ID, r.Address.Street, r.Address.City, r.ListPrice, r.Value, r.Low, r.High, r.Recommended,
+				r.GapRatio*100, r.Position, r.Comparables, r.Confidence)
+		}
+		w.Flush()
+	}
+
+	fmt.Fprintf(os.Stderr, "Active listings: %d  valued: %d  no comparables: %d  failed: %d  listed: %d\n",
+		summary.Total, summary.Valued, summary.NoComparables, summary.Failed, len(results))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/backtest"
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/tuning"
+)
+
+// runTune searches the configuration space against the closed sales in the
+// market data and writes the tuned configuration to a file
+func runTune(args []string) {
+	fs := flag.NewFlagSet("tune", flag.ExitOnError)
+	configPath := fs.String("config", config.DefaultPath, "path to the configuration file")
+	dataPath := fs.String("data", listingsPath, "path or URL of the market listings (file, directory, .jsonl, .csv or http)")
+	outPath := fs.String("out", "config/application.tuned.json", "path to write the tuned configuration")
+	seed := fs.Int64("seed", 1, "random seed for the search")
+	iterations := fs.Int("iterations", 50, "maximum number of search iterations")
+	profile := fs.String("profile", "", "configuration profile to tune instead of the top-level settings")
+	config.RegisterFlags(fs)
+	fs.Parse(args)
+
+	cfg, _, err := config.LoadLayered(*configPath, config.FlagOverrides(fs))
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	// a profile is tuned as a configuration of its own, written without profiles
+	if *profile != "" {
+		if cfg, err = cfg.Profile(*profile); err != nil {
+			log.Fatalf("Error selecting configuration profile: %v", err)
+		}
+	}
+
+	listings, err := readListings(*dataPath)
+	if err != nil {
+		log.Fatalf("Error reading market listings: %v", err)
+	}
+
+	tuner := tuning.NewTuner(listings, *seed)
+	tuner.Iterations = *iterations
+	result := tuner.Tune(cfg)
+
+	data, err := json.MarshalIndent(result.Config, "", "  ")
+	if err != nil {
+		log.Fatalf("Error encoding tuned configuration: %v", err)
+	}
+	if err := os.WriteFile(*outPath, append(data, '\n'), 0644); err != nil {
+		log.Fatalf("Error writing tuned configuration: %v", err)
+	}
+
+	printMetrics("Before", result.Before)
+	printMetrics("After", result.After)
+	fmt.Printf("Tuned configuration written to %s\n", *outPath)
+}
+
+func printMetrics(label string, m backtest.Metrics) {
+	fmt.Printf("%-7s MdAPE: %6.2f%%  MAPE: %6.2f%%  valued: %d  skipped: %d\n",
+		label+":", m.MdAPE*100, m.MAPE*100, m.Count, m.Skipped)
+}
@@ -1,3 +1,7 @@
 module github.com/krlosmederos/locqube-challenge
 
 go 1.23
+
+require go.etcd.io/bbolt v1.3.11
+
+require golang.org/x/sys v0.4.0 // indirect
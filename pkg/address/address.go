@@ -0,0 +1,322 @@
+package address
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+// Line is a delivery line split into its parts in USPS standard form
+type Line struct {
+	Number        string
+	PreDirection  string
+	Name          string
+	Suffix        string
+	PostDirection string
+	Unit          string
+}
+
+// ParseLine splits a delivery line such as "9 Thorncrest Ridge Unit Lot #52"
+// into its number, street name, suffix and unit, abbreviating the suffix and
+// directions the USPS way and dropping the unit designators
+func ParseLine(s string) Line {
+	words := tokens(s)
+
+	// a designator needs a number and a street name before it so that
+	// "12 Lot Rd" is not read as a unit
+	unit := len(words)
+	for i := 2; i < len(words); i++ {
+		if isUnitMarker(words, i) {
+			unit = i
+			break
+		}
+	}
+
+	var line Line
+	var units []string
+	for _, w := range words[unit:] {
+		if !unitDesignators[w] && w != "#" {
+			units = append(units, w)
+		}
+	}
+	line.Unit = strings.Join(units, " ")
+
+	street := words[:unit]
+	if len(street) > 0 && startsWithDigit(street[0]) {
+		line.Number = street[0]
+		street = street[1:]
+	}
+	if len(street) > 1 {
+		if d, ok := directions[street[0]]; ok {
+			line.PreDirection = d
+			street = street[1:]
+		}
+	}
+	if len(street) > 1 {
+		if d, ok := directions[street[len(street)-1]]; ok {
+			line.PostDirection = d
+			street = street[:len(street)-1]
+		}
+	}
+	if len(street) > 1 {
+		if s, ok := suffixes[street[len(street)-1]]; ok {
+			line.Suffix = s
+			street = street[:len(street)-1]
+		}
+	}
+	line.Name = strings.Join(street, " ")
+	return line
+}
+
+// isUnitMarker reports whether the word at i starts the unit of a line. A
+// "#" does when a value follows it, a designator only when it also follows
+// the street suffix or direction and the line does not end with another
+// suffix, so "12 Green Lot Rd" and "400 Saint Lot Way" have no unit.
+func isUnitMarker(words []string, i int) bool {
+	if words[i] != "#" && !unitDesignators[words[i]] {
+		return false
+	}
+
+	hasValue := false
+	for _, w := range words[i+1:] {
+		if w != "#" && !unitDesignators[w] {
+			hasValue = true
+		}
+	}
+	if !hasValue || words[i] == "#" {
+		return hasValue
+	}
+
+	_, afterSuffix := suffixes[words[i-1]]
+	_, afterDirection := directions[words[i-1]]
+	_, endsWithSuffix := suffixes[words[len(words)-1]]
+	return (afterSuffix || afterDirection) && !endsWithSuffix
+}
+
+// String returns the line in standard form, as in "9 THORNCREST RDG #52"
+func (l Line) String() string {
+	var parts []string
+	for _, p := range []string{l.Number, l.PreDirection, l.Name, l.Suffix, l.PostDirection} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if l.Unit != "" {
+		parts = append(parts, "#"+l.Unit)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Street returns a delivery line in standard form so that "1 Blackstone
+// Court Unit 1" and "1 Blackstone Ct #1" are equal
+func Street(s string) string {
+	return ParseLine(s).String()
+}
+
+// City returns a city name in standard form so that "Danbury", "DANBURY"
+// and "Danbury City" are equal. The St, Mt and Ft abbreviations are expanded
+// and a trailing City, Town or Village is dropped from the cities set with
+// SetDesignatedCities only, "Union City" and "Union" are different cities.
+func City(s string) string {
+	if city, ok := cities.load(s); ok {
+		return city
+	}
+	return cities.store(s, cityName(s))
+}
+
+// cityName returns a city name upper-cased with its leading abbreviation expanded
+func cityName(s string) string {
+	words := tokens(s)
+	if len(words) > 1 {
+		if w, ok := cityPrefixes[words[0]]; ok {
+			words[0] = w
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// CityBase returns the standard form of a city name without a trailing
+// designation such as TOWN, whatever cities are designated. Every way of
+// writing a city has the same base, so indexes keyed on it do not change
+// with the configuration, but different cities may share a base too.
+func CityBase(s string) string {
+	city := cityName(s)
+	for _, suffix := range citySuffixes {
+		if base, ok := strings.CutSuffix(city, " "+suffix); ok && base != "" {
+			return base
+		}
+	}
+	return city
+}
+
+// CitySpellings returns the upper-cased spellings a city name starts with in
+// feeds: the name without a trailing designation, with its leading
+// abbreviation written out and abbreviated. Unlike City the punctuation is
+// kept, so a remote source can match them against the names it stores.
+func CitySpellings(s string) []string {
+	words := strings.Fields(strings.ToUpper(s))
+	if len(words) > 1 {
+		for _, suffix := range citySuffixes {
+			if words[len(words)-1] == suffix {
+				words = words[:len(words)-1]
+				break
+			}
+		}
+	}
+	if len(words) == 0 {
+		return nil
+	}
+
+	rest := strings.Join(words[1:], " ")
+	spelling := func(first string) string {
+		if rest == "" {
+			return first
+		}
+		return first + " " + rest
+	}
+
+	spellings := []string{spelling(words[0])}
+	if len(words) > 1 {
+		first := strings.TrimSuffix(words[0], ".")
+		for abbreviation, word := range cityPrefixes {
+			if first != abbreviation && first != word {
+				continue
+			}
+			for _, w := range []string{word, abbreviation, abbreviation + "."} {
+				if w != words[0] {
+					spellings = append(spellings, spelling(w))
+				}
+			}
+		}
+	}
+	return spellings
+}
+
+// SetDesignatedCities sets the municipalities whose name feeds also write
+// with a designation, such as "Danbury Town" for Danbury, replacing the
+// previous ones. The configuration sets them when it is loaded.
+func SetDesignatedCities(names []string) {
+	aliases := map[string]string{}
+	for _, name := range names {
+		city := cityName(name)
+		if city == "" {
+			continue
+		}
+		for _, suffix := range citySuffixes {
+			aliases[city+" "+suffix] = city
+		}
+	}
+
+	cities.mu.Lock()
+	defer cities.mu.Unlock()
+	cities.aliases = aliases
+	cities.names = map[string]string{}
+}
+
+// maxCachedCities bounds the city cache, names are read from feeds and
+// requests so their number is not
+const maxCachedCities = 4096
+
+// cityCache holds the standard form of the city names seen last, a market
+// has few of them and every listing is matched on its city. The cache is
+// emptied when it is full or the designated cities change.
+type cityCache struct {
+	mu      sync.RWMutex
+	names   map[string]string
+	aliases map[string]string
+}
+
+var cities = &cityCache{names: map[string]string{}}
+
+func (c *cityCache) load(s string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	city, ok := c.names[s]
+	return city, ok
+}
+
+// store resolves the designated form of a city name and caches the result
+func (c *cityCache) store(s, city string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if alias, ok := c.aliases[city]; ok {
+		city = alias
+	}
+	if len(c.names) >= maxCachedCities {
+		c.names = map[string]string{}
+	}
+	c.names[s] = city
+	return city
+}
+
+// SameCity reports whether two city names are the same city
+func SameCity(a, b string) bool {
+	return a == b || City(a) == City(b)
+}
+
+// Of returns the address of a listing with its delivery line as street,
+// the standardized address when the feed provides one
+func Of(p models.Property) models.Address {
+	a := p.Address
+	if p.StdAddress != nil {
+		a = *p.StdAddress
+	}
+	if a.DeliveryLine != "" {
+		a.Street = a.DeliveryLine
+	}
+	return a
+}
+
+// Key returns the canonical key of the property of a listing, its street
+// in standard form and its zip code or city, empty when it has no street
+func Key(p models.Property) string {
+	a := Of(p)
+	street := Street(a.Street)
+	if street == "" {
+		return ""
+	}
+
+	area := a.Zip
+	if len(area) > 5 {
+		area = area[:5]
+	}
+	if area == "" {
+		area = City(a.City)
+	}
+	return street + "|" + area
+}
+
+// tokens upper-cases the text and splits it into words of letters and
+// digits, a "#" is a word of its own
+func tokens(s string) []string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range strings.ToUpper(s) {
+		switch {
+		case r == '#':
+			flush()
+			words = append(words, "#")
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '/':
+			word.WriteRune(r)
+		case r == '.' || r == '\'':
+			// "St." and "Joe's" keep their word
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
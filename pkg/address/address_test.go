@@ -0,0 +1,207 @@
+package address
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want Line
+	}{
+		{line: "3 Boyce Road", want: Line{Number: "3", Name: "BOYCE", Suffix: "RD"}},
+		{line: "2 Joes Hill Rd", want: Line{Number: "2", Name: "JOES HILL", Suffix: "RD"}},
+		{line: "9 Thorncrest Ridge Unit Lot #52", want: Line{Number: "9", Name: "THORNCREST", Suffix: "RDG", Unit: "52"}},
+		{line: "1 Blackstone Court Unit 1", want: Line{Number: "1", Name: "BLACKSTONE", Suffix: "CT", Unit: "1"}},
+		{line: "100 North Main Street, Apt. 4B", want: Line{Number: "100", PreDirection: "N", Name: "MAIN", Suffix: "ST", Unit: "4B"}},
+		{line: "12 Lot Rd", want: Line{Number: "12", Name: "LOT", Suffix: "RD"}},
+		{line: "12 Green Lot Rd", want: Line{Number: "12", Name: "GREEN LOT", Suffix: "RD"}},
+		{line: "400 Saint Lot Way", want: Line{Number: "400", Name: "SAINT LOT", Suffix: "WAY"}},
+		{line: "15 Elm St North Suite 200", want: Line{Number: "15", Name: "ELM", Suffix: "ST", PostDirection: "N", Unit: "200"}},
+		{line: "22 Main #3", want: Line{Number: "22", Name: "MAIN", Unit: "3"}},
+		{line: "5 Park Avenue East", want: Line{Number: "5", Name: "PARK", Suffix: "AVE", PostDirection: "E"}},
+		{line: "7 Court", want: Line{Number: "7", Name: "COURT"}},
+		{line: "  ", want: Line{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := ParseLine(tt.line); got != tt.want {
+				t.Errorf("ParseLine(%q) = %+v, want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreet(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{a: "10 Fox Den Rd", b: "10 FOX DEN ROAD", same: true},
+		{a: "1 Blackstone Court Unit 1", b: "1 Blackstone Ct #1", same: true},
+		{a: "9 Thorncrest Ridge Unit Lot #52", b: "9 Thorncrest Rdg Apt 52", same: true},
+		{a: "46 Deer Hill Ave.", b: "46  deer hill avenue", same: true},
+		{a: "1 Blackstone Ct Unit 1", b: "1 Blackstone Ct Unit 2", same: false},
+		{a: "12 Main St", b: "12 Main Rd", same: false},
+		{a: "12 Green Lot Rd", b: "12 Green Lot Road", same: true},
+		{a: "12 Green Lot Rd", b: "12 Green Rd", same: false},
+		{a: "400 Saint Lot Way", b: "400 Saint Way", same: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if same := Street(tt.a) == Street(tt.b); same != tt.same {
+				t.Errorf("Street(%q) = %q, Street(%q) = %q, want same %v", tt.a, Street(tt.a), tt.b, Street(tt.b), tt.same)
+			}
+		})
+	}
+
+	if got := Street("9 Thorncrest Ridge Unit Lot #52"); got != "9 THORNCREST RDG #52" {
+		t.Errorf("Street() = %q, want %q", got, "9 THORNCREST RDG #52")
+	}
+	if got := Street("12 Green Lot Rd"); got != "12 GREEN LOT RD" {
+		t.Errorf("Street() = %q, want %q", got, "12 GREEN LOT RD")
+	}
+}
+
+func TestSameCity(t *testing.T) {
+	SetDesignatedCities([]string{"Danbury", "Norwalk"})
+	t.Cleanup(func() { SetDesignatedCities(nil) })
+
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "Danbury", b: "DANBURY", want: true},
+		{a: "Danbury", b: "Danbury City", want: true},
+		{a: " danbury ", b: "Danbury Town", want: true},
+		{a: "St. Louis", b: "Saint Louis", want: true},
+		{a: "Danbury", b: "Norwalk", want: false},
+		{a: "New Fairfield", b: "Fairfield", want: false},
+		{a: "City", b: "", want: false},
+		{a: "Union City", b: "Union", want: false},
+		{a: "Jersey City", b: "Jersey", want: false},
+		{a: "Carson City", b: "Carson", want: false},
+		{a: "Norwalk Town", b: "Norwalk City", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := SameCity(tt.a, tt.b); got != tt.want {
+				t.Errorf("SameCity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCityBase(t *testing.T) {
+	tests := []struct {
+		city string
+		want string
+	}{
+		{city: "Danbury", want: "DANBURY"},
+		{city: "Danbury Town", want: "DANBURY"},
+		{city: "Union City", want: "UNION"},
+		{city: "City", want: "CITY"},
+		{city: "St. Louis", want: "SAINT LOUIS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.city, func(t *testing.T) {
+			if got := CityBase(tt.city); got != tt.want {
+				t.Errorf("CityBase(%q) = %q, want %q", tt.city, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCitySpellings(t *testing.T) {
+	tests := []struct {
+		city string
+		want []string
+	}{
+		{city: "Danbury", want: []string{"DANBURY"}},
+		{city: " danbury  town ", want: []string{"DANBURY"}},
+		{city: "Coeur d'Alene", want: []string{"COEUR D'ALENE"}},
+		{city: "Saint Louis", want: []string{"SAINT LOUIS", "ST LOUIS", "ST. LOUIS"}},
+		{city: "Mt. Kisco", want: []string{"MT. KISCO", "MOUNT KISCO", "MT KISCO"}},
+		{city: "Town", want: []string{"TOWN"}},
+		{city: " ", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.city, func(t *testing.T) {
+			if got := CitySpellings(tt.city); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CitySpellings(%q) = %q, want %q", tt.city, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCity_CacheIsBounded(t *testing.T) {
+	SetDesignatedCities([]string{"Danbury"})
+	t.Cleanup(func() { SetDesignatedCities(nil) })
+
+	for i := 0; i < 3*maxCachedCities; i++ {
+		City(fmt.Sprintf("City %d", i))
+	}
+	if n := len(cities.names); n > maxCachedCities {
+		t.Errorf("city cache holds %d names, want at most %d", n, maxCachedCities)
+	}
+	if got := City("Danbury Town"); got != "DANBURY" {
+		t.Errorf("City() after the cache was emptied = %q, want DANBURY", got)
+	}
+}
+
+func TestKey(t *testing.T) {
+	SetDesignatedCities([]string{"Danbury"})
+	t.Cleanup(func() { SetDesignatedCities(nil) })
+
+	tests := []struct {
+		name string
+		p    models.Property
+		want string
+	}{
+		{
+			name: "street and zip",
+			p:    models.Property{Address: models.Address{City: "Danbury", Zip: "06810-1234", Street: "3 Boyce Road"}},
+			want: "3 BOYCE RD|06810",
+		},
+		{
+			name: "delivery line over street",
+			p:    models.Property{Address: models.Address{City: "Danbury", Zip: "06810", Street: "3 Boyce", DeliveryLine: "3 Boyce Rd"}},
+			want: "3 BOYCE RD|06810",
+		},
+		{
+			name: "standardized address",
+			p: models.Property{
+				Address:    models.Address{City: "Danbury", Street: "3 Boyce"},
+				StdAddress: &models.Address{City: "Danbury", Zip: "06811", DeliveryLine: "3 Boyce Rd"},
+			},
+			want: "3 BOYCE RD|06811",
+		},
+		{
+			name: "city without a zip",
+			p:    models.Property{Address: models.Address{City: "Danbury City", Street: "3 Boyce Rd"}},
+			want: "3 BOYCE RD|DANBURY",
+		},
+		{
+			name: "no street",
+			p:    models.Property{Address: models.Address{City: "Danbury", Zip: "06810"}},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Key(tt.p); got != tt.want {
+				t.Errorf("Key() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
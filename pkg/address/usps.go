@@ -0,0 +1,84 @@
+package address
+
+// suffixes maps street suffixes and their common variants to the USPS
+// standard abbreviation (Publication 28, appendix C1)
+var suffixes = map[string]string{
+	"ALLEY": "ALY", "ALY": "ALY",
+	"AVENUE": "AVE", "AVE": "AVE", "AV": "AVE",
+	"BEND": "BND", "BND": "BND",
+	"BOULEVARD": "BLVD", "BLVD": "BLVD",
+	"BRIDGE": "BRG", "BRG": "BRG",
+	"BROOK": "BRK", "BRK": "BRK",
+	"CIRCLE": "CIR", "CIR": "CIR",
+	"COURT": "CT", "CT": "CT",
+	"COVE": "CV", "CV": "CV",
+	"CRESCENT": "CRES", "CRES": "CRES",
+	"CROSSING": "XING", "XING": "XING",
+	"DRIVE": "DR", "DR": "DR",
+	"EXTENSION": "EXT", "EXT": "EXT",
+	"GLEN": "GLN", "GLN": "GLN",
+	"GREEN": "GRN", "GRN": "GRN",
+	"GROVE": "GRV", "GRV": "GRV",
+	"HEIGHTS": "HTS", "HTS": "HTS",
+	"HIGHWAY": "HWY", "HWY": "HWY",
+	"HILL": "HL", "HL": "HL",
+	"HOLLOW": "HOLW", "HOLW": "HOLW",
+	"LANE": "LN", "LN": "LN",
+	"LOOP":   "LOOP",
+	"MEADOW": "MDW", "MDW": "MDW",
+	"MEADOWS": "MDWS", "MDWS": "MDWS",
+	"PARK":    "PARK",
+	"PARKWAY": "PKWY", "PKWY": "PKWY",
+	"PATH":  "PATH",
+	"PIKE":  "PIKE",
+	"PLACE": "PL", "PL": "PL",
+	"PLAZA": "PLZ", "PLZ": "PLZ",
+	"POINT": "PT", "PT": "PT",
+	"RIDGE": "RDG", "RDG": "RDG",
+	"ROAD": "RD", "RD": "RD",
+	"ROW":    "ROW",
+	"RUN":    "RUN",
+	"SQUARE": "SQ", "SQ": "SQ",
+	"STREET": "ST", "ST": "ST", "STR": "ST",
+	"TERRACE": "TER", "TER": "TER",
+	"TRAIL": "TRL", "TRL": "TRL",
+	"TURNPIKE": "TPKE", "TPKE": "TPKE",
+	"VIEW": "VW", "VW": "VW",
+	"VILLAGE": "VLG", "VLG": "VLG",
+	"WALK": "WALK",
+	"WAY":  "WAY",
+}
+
+// directions maps the directions to their USPS abbreviation
+var directions = map[string]string{
+	"NORTH": "N", "N": "N",
+	"SOUTH": "S", "S": "S",
+	"EAST": "E", "E": "E",
+	"WEST": "W", "W": "W",
+	"NORTHEAST": "NE", "NE": "NE",
+	"NORTHWEST": "NW", "NW": "NW",
+	"SOUTHEAST": "SE", "SE": "SE",
+	"SOUTHWEST": "SW", "SW": "SW",
+}
+
+// unitDesignators are the words introducing a unit, all of them are
+// written as "#" in standard form
+var unitDesignators = map[string]bool{
+	"APARTMENT": true, "APT": true,
+	"BUILDING": true, "BLDG": true,
+	"FLOOR": true, "FL": true,
+	"LOT":  true,
+	"ROOM": true, "RM": true,
+	"SPACE": true, "SPC": true,
+	"SUITE": true, "STE": true,
+	"UNIT": true,
+}
+
+// citySuffixes are the municipal designations feeds append to a city name,
+// as in "Danbury City" or "Bethel Town"
+var citySuffixes = []string{"CITY", "TOWN", "TOWNSHIP", "VILLAGE", "BOROUGH"}
+
+// cityPrefixes expands the abbreviations leading a city name
+var cityPrefixes = map[string]string{
+	"ST": "SAINT", "STE": "SAINTE", "MT": "MOUNT", "FT": "FORT",
+}
@@ -0,0 +1,46 @@
+package algorithm
+
+import (
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+// NewRentalValuation creates a valuation of the subject's monthly rent from
+// the lease listings. It uses the named profile, or the rental profile when
+// profile is empty and the configuration defines one, else the default one.
+func NewRentalValuation(subject models.Property, listings []models.Property, cfg *config.Config, profile string) (*Valuation, error) {
+	if profile == "" {
+		profile = config.DefaultProfile
+		if _, ok := cfg.Profiles[config.RentalProfile]; ok {
+			profile = config.RentalProfile
+		}
+	}
+
+	valuation, err := NewValuationWithProfile(subject, listings, cfg, profile)
+	if err != nil {
+		return nil, err
+	}
+	// the filter shares the resolved configuration
+	valuation.Config.ValuationType = config.ValuationRent
+	return valuation, nil
+}
+
+// Yield is the return of renting a property at the estimated rent
+type Yield struct {
+	AnnualRent float64 `json:"annualRent"`
+	GrossYield float64 `json:"grossYield"`
+	CapRate    float64 `json:"capRate"`
+}
+
+// RentalYield returns the gross yield, the annual rent relative to the
+// value, and the cap rate, the annual rent less the expense ratio relative
+// to the value. The yields are 0 without a value.
+func RentalYield(monthlyRent, value, expenseRatio float64) Yield {
+	yield := Yield{AnnualRent: monthlyRent * 12}
+	if value <= 0 {
+		return yield
+	}
+	yield.GrossYield = yield.AnnualRent / value
+	yield.CapRate = yield.AnnualRent * (1 - expenseRatio) / value
+	return yield
+}
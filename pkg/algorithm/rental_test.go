@@ -0,0 +1,133 @@
+package algorithm
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/source"
+)
+
+func TestNewRentalValuation(t *testing.T) {
+	now := time.Now().Unix()
+	oneMonthAgo := now - (30 * 24 * 60 * 60)
+
+	lease := func(rent float64) models.Property {
+		p := createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Closed", oneMonthAgo, oneMonthAgo, rent, rent)
+		p.ListingType = "Residential Lease"
+		return p
+	}
+	listings := []models.Property{
+		lease(3000),
+		lease(3400),
+		createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Closed", oneMonthAgo, oneMonthAgo, 500000, 500000),
+	}
+	subject := createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Active", now, 0, 0, 0)
+
+	cfg := &config.Config{MinSalesCount: 1}
+	cfg.CriteriaWeights.Status = 1.0
+	cfg.StatusScores.Sold = 1.0
+
+	tests := []struct {
+		name        string
+		profiles    map[string]config.Profile
+		profile     string
+		wantProfile string
+	}{
+		{name: "without a rental profile", wantProfile: config.DefaultProfile},
+		{
+			name: "rental profile",
+			profiles: map[string]config.Profile{
+				config.RentalProfile: {Settings: json.RawMessage(`{"min_sales_count": 2}`)},
+			},
+			wantProfile: config.RentalProfile,
+		},
+		{
+			name: "named profile",
+			profiles: map[string]config.Profile{
+				config.RentalProfile: {},
+				"investor":           {Settings: json.RawMessage(`{"min_sales_count": 2}`)},
+			},
+			profile:     "investor",
+			wantProfile: "investor",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withProfiles := *cfg
+			withProfiles.Profiles = tt.profiles
+
+			valuation, err := NewRentalValuation(subject, listings, &withProfiles, tt.profile)
+			if err != nil {
+				t.Fatalf("NewRentalValuation() error = %v", err)
+			}
+			result := valuation.Estimate()
+
+			if result.Profile != tt.wantProfile {
+				t.Errorf("Profile = %v, want %v", result.Profile, tt.wantProfile)
+			}
+			if result.Value != 3200 || len(result.Comparables) != 2 {
+				t.Errorf("Value = %v from %d comparables, want 3200 from the 2 leases", result.Value, len(result.Comparables))
+			}
+		})
+	}
+
+	if got := NewValuationWithConfig(subject, listings, cfg).Calculate(); got != 500000 {
+		t.Errorf("sale Calculate() = %v, want 500000 without the leases", got)
+	}
+	if cfg.ValuationType != "" {
+		t.Errorf("NewRentalValuation() modified the configuration: valuation_type = %q", cfg.ValuationType)
+	}
+}
+
+func TestRentalYield(t *testing.T) {
+	yield := RentalYield(3000, 600000, 0.4)
+	if yield.AnnualRent != 36000 || math.Abs(yield.GrossYield-0.06) > 1e-9 || math.Abs(yield.CapRate-0.036) > 1e-9 {
+		t.Errorf("RentalYield() = %+v, want 36000 a year, 6%% gross and 3.6%% cap rate", yield)
+	}
+	if yield := RentalYield(3000, 0, 0.4); yield.GrossYield != 0 || yield.CapRate != 0 {
+		t.Errorf("RentalYield() without a value = %+v, want no yields", yield)
+	}
+}
+
+func TestNewRentalValuation_SampleData(t *testing.T) {
+	cfg, err := config.Load("../../config/application.json")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	listings, err := source.All(&source.File{Path: "../../data/rental_listings.json"})
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+
+	var subject models.Property
+	for _, p := range listings {
+		if p.ID == "R-2001" {
+			subject = p
+		}
+	}
+	if !subject.IsLease() {
+		t.Fatal("sample lease R-2001 not found")
+	}
+
+	rental, err := NewRentalValuation(subject, listings, cfg, "")
+	if err != nil {
+		t.Fatalf("NewRentalValuation() error = %v", err)
+	}
+	rental.AsOf = time.Date(2025, 3, 1, 23, 59, 59, 0, time.UTC)
+	result := rental.Estimate()
+
+	// the sample leases rent for $3,050 to $3,550 a month
+	if result.Value < 3050 || result.Value > 3550 || result.Profile != config.RentalProfile {
+		t.Errorf("Estimate() = %v with profile %s, want a rent between 3050 and 3550 with the rental profile", result.Value, result.Profile)
+	}
+	for _, c := range result.Comparables {
+		if c.ID[0] != 'R' {
+			t.Errorf("sale %s is a comparable of the rent estimate", c.ID)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package algorithm
+
+import (
+	"sort"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+// saleToListRatio returns the median sale-to-list ratio of the closed
+// comparables, 0 when the ratio is off or fewer than sale_to_list.min_sales
+// comparables have both prices
+func (v *Valuation) saleToListRatio(comps []models.Property) float64 {
+	mode := v.Config.SaleToList.Mode
+	if mode == "" || mode == config.SaleToListOff {
+		return 0
+	}
+
+	var ratios []float64
+	for _, p := range comps {
+		list := listReference(p, mode)
+		if p.Status == "Closed" && p.SalePrice > 0 && list > 0 {
+			ratios = append(ratios, p.SalePrice/list)
+		}
+	}
+
+	minSales := v.Config.SaleToList.MinSales
+	if minSales < 1 {
+		minSales = 1
+	}
+	if len(ratios) < minSales {
+		return 0
+	}
+
+	sort.Float64s(ratios)
+	middle := len(ratios) / 2
+	if len(ratios)%2 == 0 {
+		return (ratios[middle-1] + ratios[middle]) / 2
+	}
+	return ratios[middle]
+}
+
+// listReference returns the list price the ratio is computed against, the
+// previous list price in original mode when the price was reduced
+func listReference(p models.Property, mode string) float64 {
+	if mode == config.SaleToListOriginal && p.PreviousListPrice > p.ListPrice {
+		return p.PreviousListPrice
+	}
+	return p.ListPrice
+}
+
+// comparablePrice returns the price a comparable contributes, the expected
+// sale price of a listing that has not sold when there is a ratio
+func (v *Valuation) comparablePrice(comp models.Property, ratio float64) float64 {
+	if ratio == 0 || comp.SalePrice > 0 || comp.Status == "Closed" {
+		return comp.GetPrice()
+	}
+	return listReference(comp, v.Config.SaleToList.Mode) * ratio
+}
@@ -0,0 +1,70 @@
+package algorithm
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+func TestValuation_SaleToListRatio(t *testing.T) {
+	now := time.Now().Unix()
+	oneMonthAgo := now - (30 * 24 * 60 * 60)
+
+	sale := func(list, previous, sold float64) models.Property {
+		p := createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Closed", oneMonthAgo, oneMonthAgo, list, sold)
+		p.PreviousListPrice = previous
+		return p
+	}
+	active := createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Active", oneMonthAgo, 0, 500000, 0)
+	active.PreviousListPrice = 550000
+
+	listings := []models.Property{
+		sale(500000, 0, 450000),
+		sale(500000, 625000, 500000),
+		sale(400000, 0, 380000),
+		active,
+	}
+	subject := createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Active", now, 0, 0, 0)
+
+	tests := []struct {
+		name      string
+		mode      string
+		minSales  int
+		wantRatio float64
+		wantPrice float64
+	}{
+		{name: "off", mode: config.SaleToListOff, wantRatio: 0, wantPrice: 500000},
+		{name: "unset", mode: "", wantRatio: 0, wantPrice: 500000},
+		{name: "list price", mode: config.SaleToListList, minSales: 3, wantRatio: 0.95, wantPrice: 475000},
+		{name: "original list price", mode: config.SaleToListOriginal, minSales: 3, wantRatio: 0.9, wantPrice: 495000},
+		{name: "too few sales", mode: config.SaleToListList, minSales: 4, wantRatio: 0, wantPrice: 500000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MinSalesCount: 1}
+			cfg.CriteriaWeights.Status = 1.0
+			cfg.StatusScores.Sold = 1.0
+			cfg.StatusScores.Active = 1.0
+			cfg.SaleToList.Mode = tt.mode
+			cfg.SaleToList.MinSales = tt.minSales
+
+			result := NewValuationWithConfig(subject, listings, cfg).Estimate()
+
+			if math.Abs(result.SaleToListRatio-tt.wantRatio) > 1e-9 {
+				t.Errorf("SaleToListRatio = %v, want %v", result.SaleToListRatio, tt.wantRatio)
+			}
+			for _, c := range result.Comparables {
+				if c.Status == "Active" && math.Abs(c.Price-tt.wantPrice) > 1e-6 {
+					t.Errorf("active comparable price = %v, want %v", c.Price, tt.wantPrice)
+				}
+				if c.Status == "Closed" && c.Price != 450000 && c.Price != 500000 && c.Price != 380000 {
+					t.Errorf("closed comparable price = %v, want its sale price", c.Price)
+				}
+			}
+		})
+	}
+}
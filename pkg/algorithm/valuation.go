@@ -2,12 +2,16 @@ package algorithm
 
 import (
 	"fmt"
-	"sync"
+	"math"
+	"sort"
+	"time"
 
 	"github.com/krlosmederos/locqube-challenge/pkg/config"
 	"github.com/krlosmederos/locqube-challenge/pkg/criteria"
 	"github.com/krlosmederos/locqube-challenge/pkg/filters"
 	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/source"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
 )
 
 type CriteriaEvaluator interface {
@@ -17,82 +21,209 @@ type CriteriaEvaluator interface {
 type Valuation struct {
 	Subject  models.Property
 	Listings []models.Property
-	Config   *config.Config
-	filter   *filters.PropertyFilter
+	// Store is queried for comparables instead of scanning Listings when set
+	Store   *store.Store
+	Config  *config.Config
+	Profile string
+	// AsOf is the date the subject is valued at, the recency of the
+	// comparables is measured from it. Now when zero.
+	AsOf   time.Time
+	filter *filters.PropertyFilter
+}
+
+// Result holds the estimated value, its range and the comparables it was
+// calculated from. The range is the estimate plus and minus the weighted
+// standard deviation of the comparable prices. SaleToListRatio is the median
+// sale-to-list ratio of the closed comparables applied to the list price of
+// the others, 0 when none was applied.
+type Result struct {
+	Value           float64      `json:"value"`
+	Low             float64      `json:"low"`
+	High            float64      `json:"high"`
+	Profile         string       `json:"profile"`
+	SaleToListRatio float64      `json:"saleToListRatio,omitempty"`
+	Comparables     []Comparable `json:"comparables"`
+}
+
+// Comparable is a listing used in a valuation with the price and weight it contributed
+type Comparable struct {
+	ID        string         `json:"id"`
+	Address   models.Address `json:"address"`
+	Status    string         `json:"status"`
+	Size      float64        `json:"size"`
+	Beds      int            `json:"beds"`
+	Baths     float64        `json:"baths"`
+	YearBuilt int            `json:"yearBuilt"`
+	Price     float64        `json:"price"`
+	Weight    float64        `json:"weight"`
+}
+
+// PriceGap returns how much a list price is above the estimate, negative
+// when it is below, and that difference relative to the estimate. Both are
+// 0 without an estimate.
+func (r Result) PriceGap(listPrice float64) (float64, float64) {
+	if r.Value <= 0 {
+		return 0, 0
+	}
+	diff := listPrice - r.Value
+	return diff, diff / r.Value
 }
 
 func NewValuation(subject models.Property, listings []models.Property) *Valuation {
-	cfg := config.GetConfig()
+	return NewValuationWithConfig(subject, listings, config.GetConfig())
+}
+
+// NewValuationWithConfig creates a valuation that uses the given configuration
+// instead of the global one, the profile matching the subject's address is
+// selected automatically and the default profile is used if it cannot be resolved
+func NewValuationWithConfig(subject models.Property, listings []models.Property, cfg *config.Config) *Valuation {
+	valuation, err := NewValuationWithProfile(subject, listings, cfg, cfg.SelectProfile(Location(subject)))
+	if err != nil {
+		valuation, _ = NewValuationWithProfile(subject, listings, cfg, config.DefaultProfile)
+	}
+	return valuation
+}
+
+// NewValuationWithStore creates a valuation that queries the store indexes
+// for comparables, the profile is selected as in NewValuationWithConfig
+func NewValuationWithStore(subject models.Property, s *store.Store, cfg *config.Config) *Valuation {
+	valuation := NewValuationWithConfig(subject, nil, cfg)
+	valuation.Store = s
+	return valuation
+}
+
+// NewValuationWithSource creates a valuation from the listings the source
+// returns for the subject's comparable query, the profile is selected as in
+// NewValuationWithConfig
+func NewValuationWithSource(subject models.Property, src source.ListingSource, cfg *config.Config) (*Valuation, error) {
+	valuation := NewValuationWithConfig(subject, nil, cfg)
+
+	listings, err := src.Listings(valuation.filter.Query())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query listings: %v", err)
+	}
+	valuation.Listings = listings
+
+	return valuation, nil
+}
+
+// NewValuationWithProfile creates a valuation that uses the named profile of
+// the configuration regardless of the subject's address
+func NewValuationWithProfile(subject models.Property, listings []models.Property, cfg *config.Config, profile string) (*Valuation, error) {
+	resolved, err := cfg.Profile(profile)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Valuation{
 		Subject:  subject,
 		Listings: listings,
-		Config:   cfg,
-		filter:   filters.NewPropertyFilter(subject, cfg),
+		Config:   resolved,
+		Profile:  profile,
+		filter:   filters.NewPropertyFilter(subject, resolved),
+	}, nil
+}
+
+// Location returns the location of a property used to select a configuration profile
+func Location(p models.Property) config.Location {
+	return config.Location{
+		State:  p.Address.State,
+		County: p.County,
+		City:   p.Address.City,
+		Zip:    p.Address.Zip,
 	}
 }
 
 // Calculate calculates the valuation of the subject property
 func (v *Valuation) Calculate() float64 {
-	type priceResult struct {
-		price  float64
-		weight float64
-	}
-
-	filteredListings := v.filter.Filter(v.Listings)
-
-	results := make(chan priceResult, len(filteredListings))
-	var wg sync.WaitGroup
+	return v.Estimate().Value
+}
 
-	for _, prop := range filteredListings {
-		wg.Add(1)
-		go func(comp models.Property) {
-			defer wg.Done()
+// Estimate calculates the valuation of the subject property and records
+// how it was produced. Comparables are weighted sequentially, callers valuing
+// many subjects should run valuations concurrently instead.
+func (v *Valuation) Estimate() Result {
+	result := Result{Profile: v.Profile}
 
-			weight, err := v.calculateWeight(comp)
-			if err != nil {
-				fmt.Printf("Error calculating weight for property %s: %v\n", comp.ID, err)
-				return
-			}
+	v.filter.AsOf = v.AsOf
 
-			price := comp.GetPrice()
-			results <- priceResult{price: price, weight: weight}
-		}(prop)
+	var filteredListings []models.Property
+	if v.Store != nil {
+		filteredListings = v.filter.FilterStore(v.Store)
+	} else {
+		filteredListings = v.filter.Filter(v.Listings)
 	}
 
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+	result.SaleToListRatio = v.saleToListRatio(filteredListings)
 
 	var totalWeight, weightedSum float64
-	for result := range results {
-		weightedSum += result.price * result.weight
-		totalWeight += result.weight
+	for _, comp := range filteredListings {
+		weight, err := v.calculateWeight(comp)
+		if err != nil {
+			fmt.Printf("Error calculating weight for property %s: %v\n", comp.ID, err)
+			continue
+		}
+
+		price := v.comparablePrice(comp, result.SaleToListRatio)
+		weightedSum += price * weight
+		totalWeight += weight
+		result.Comparables = append(result.Comparables, Comparable{
+			ID:        comp.ID,
+			Address:   comp.Address,
+			Status:    comp.Status,
+			Size:      comp.Size,
+			Beds:      comp.Beds,
+			Baths:     comp.Baths.Total,
+			YearBuilt: comp.YearBuilt,
+			Price:     price,
+			Weight:    weight,
+		})
 	}
 
+	sort.Slice(result.Comparables, func(i, j int) bool {
+		if result.Comparables[i].Weight != result.Comparables[j].Weight {
+			return result.Comparables[i].Weight > result.Comparables[j].Weight
+		}
+		return result.Comparables[i].ID < result.Comparables[j].ID
+	})
+
 	if totalWeight == 0 {
-		return 0
+		return result
 	}
 
-	return weightedSum / totalWeight
+	result.Value = weightedSum / totalWeight
+
+	var variance float64
+	for _, c := range result.Comparables {
+		variance += c.Weight * (c.Price - result.Value) * (c.Price - result.Value)
+	}
+	deviation := math.Sqrt(variance / totalWeight)
+	result.Low = math.Max(0, result.Value-deviation)
+	result.High = result.Value + deviation
+
+	return result
 }
 
 func (v *Valuation) calculateWeight(comp models.Property) (float64, error) {
+	recency := criteria.NewRecency(comp, v.Subject, v.Config.CriteriaWeights.Recency, criteria.TimeScores{
+		ThreeMonths: v.Config.TimeScores.ThreeMonths,
+		SixMonths:   v.Config.TimeScores.SixMonths,
+		NineMonths:  v.Config.TimeScores.NineMonths,
+	})
+	recency.AsOf = v.AsOf
+
 	criteriaList := []CriteriaEvaluator{
 		criteria.NewPropertyType(comp, v.Subject, v.Config.CriteriaWeights.PropertyType),
 		criteria.NewBedrooms(comp, v.Subject, v.Config.CriteriaWeights.Bedrooms),
 		criteria.NewBathrooms(comp, v.Subject, v.Config.CriteriaWeights.Bathrooms),
 		criteria.NewSize(comp, v.Subject, v.Config.CriteriaWeights.Size),
-		criteria.NewRecency(comp, v.Subject, v.Config.CriteriaWeights.Recency, criteria.TimeScores{
-			ThreeMonths: v.Config.TimeScores.ThreeMonths,
-			SixMonths:   v.Config.TimeScores.SixMonths,
-			NineMonths:  v.Config.TimeScores.NineMonths,
-		}),
+		recency,
 		criteria.NewStatus(comp, v.Subject, v.Config.CriteriaWeights.Status, criteria.StatusScores{
 			Sold:    v.Config.StatusScores.Sold,
 			Pending: v.Config.StatusScores.Pending,
 			Active:  v.Config.StatusScores.Active,
 		}),
+		criteria.NewNeighborhood(comp, v.Subject, v.Config.CriteriaWeights.Neighborhood),
 	}
 
 	var totalScore float64
@@ -104,5 +235,24 @@ func (v *Valuation) calculateWeight(comp models.Property) (float64, error) {
 		totalScore += score
 	}
 
-	return totalScore, nil
+	signals := criteria.NewMarketSignals(comp, criteria.SignalCurves{
+		DaysOnMarket: curve(v.Config.MarketSignals.DaysOnMarket),
+		PriceCut:     curve(v.Config.MarketSignals.PriceCut),
+		PriceCutAge:  curve(v.Config.MarketSignals.PriceCutAge),
+	})
+	signals.AsOf = v.AsOf
+	factor, err := signals.Evaluate()
+	if err != nil {
+		return 0, fmt.Errorf("error evaluating market signals: %v", err)
+	}
+
+	return totalScore * factor, nil
+}
+
+func curve(points []config.CurvePoint) criteria.Curve {
+	c := make(criteria.Curve, len(points))
+	for i, p := range points {
+		c[i] = criteria.CurvePoint{At: p.At, Factor: p.Factor}
+	}
+	return c
 }
@@ -2,6 +2,7 @@ package algorithm
 
 import (
 	"encoding/json"
+	"math"
 	"os"
 	"path/filepath"
 	"testing"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/krlosmederos/locqube-challenge/pkg/config"
 	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
 )
 
 func setupTestConfig(t *testing.T) func() {
@@ -413,3 +415,237 @@ func TestValuation_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestNewValuationWithConfig_Concurrent(t *testing.T) {
+	now := time.Now().Unix()
+	oneMonthAgo := now - (30 * 24 * 60 * 60)
+
+	subject := createTestProperty(
+		"Danbury", 2000, 4, 2.5, "Colonial", "Active",
+		now, 0, 600000, 0,
+	)
+	listings := []models.Property{
+		createTestProperty(
+			"Danbury", 2000, 4, 2.5, "Colonial", "Closed",
+			oneMonthAgo, oneMonthAgo, 600000, 600000,
+		),
+		createTestProperty(
+			"Danbury", 2000, 4, 2.5, "Colonial", "Active",
+			oneMonthAgo, 0, 700000, 0,
+		),
+	}
+
+	newConfig := func(activeScore float64) *config.Config {
+		cfg := &config.Config{MinSalesCount: 1}
+		cfg.CriteriaWeights.Status = 1.0
+		cfg.StatusScores.Sold = 1.0
+		cfg.StatusScores.Active = activeScore
+		return cfg
+	}
+
+	tests := []struct {
+		name          string
+		cfg           *config.Config
+		expectedValue float64
+	}{
+		{name: "active listings ignored", cfg: newConfig(0), expectedValue: 600000},
+		{name: "active listings weighted equally", cfg: newConfig(1), expectedValue: 650000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := NewValuationWithConfig(subject, listings, tt.cfg).Calculate()
+			if got != tt.expectedValue {
+				t.Errorf("Valuation = %v, want %v", got, tt.expectedValue)
+			}
+		})
+	}
+}
+
+func TestNewValuationWithConfig_SelectsProfile(t *testing.T) {
+	cfg := &config.Config{MinSalesCount: 1}
+	cfg.CriteriaWeights.Status = 1.0
+	cfg.StatusScores.Sold = 1.0
+	cfg.Profiles = map[string]config.Profile{
+		"danbury": {
+			Match:    config.ProfileMatch{State: "CT", City: "Danbury"},
+			Settings: json.RawMessage(`{"min_sales_count": 2}`),
+		},
+	}
+
+	subject := createTestProperty(
+		"Danbury", 2000, 4, 2.5, "Colonial", "Active",
+		0, 0, 0, 0,
+	)
+	subject.Address.State = "CT"
+
+	valuation := NewValuationWithConfig(subject, nil, cfg)
+	if valuation.Profile != "danbury" {
+		t.Errorf("Profile = %v, want danbury", valuation.Profile)
+	}
+	if valuation.Config.MinSalesCount != 2 {
+		t.Errorf("MinSalesCount = %v, want 2 from the profile", valuation.Config.MinSalesCount)
+	}
+	if got := valuation.Estimate().Profile; got != "danbury" {
+		t.Errorf("Estimate().Profile = %v, want danbury", got)
+	}
+
+	overridden, err := NewValuationWithProfile(subject, nil, cfg, config.DefaultProfile)
+	if err != nil {
+		t.Fatalf("NewValuationWithProfile() failed: %v", err)
+	}
+	if overridden.Config.MinSalesCount != 1 {
+		t.Errorf("MinSalesCount = %v, want 1 from the default profile", overridden.Config.MinSalesCount)
+	}
+
+	if _, err := NewValuationWithProfile(subject, nil, cfg, "manhattan"); err == nil {
+		t.Error("NewValuationWithProfile() expected an error for an unknown profile")
+	}
+}
+
+func TestValuation_EstimateRangeAndComparables(t *testing.T) {
+	now := time.Now().Unix()
+	oneMonthAgo := now - (30 * 24 * 60 * 60)
+
+	cfg := &config.Config{MinSalesCount: 1}
+	cfg.CriteriaWeights.Status = 1.0
+	cfg.StatusScores.Sold = 1.0
+
+	subject := createTestProperty(
+		"Danbury", 2000, 4, 2.5, "Colonial", "Active",
+		now, 0, 0, 0,
+	)
+	listings := []models.Property{
+		createTestProperty(
+			"Danbury", 2000, 4, 2.5, "Colonial", "Closed",
+			oneMonthAgo, oneMonthAgo, 500000, 500000,
+		),
+		createTestProperty(
+			"Danbury", 2000, 4, 2.5, "Colonial", "Closed",
+			oneMonthAgo, oneMonthAgo, 700000, 700000,
+		),
+	}
+	listings[0].ID = "a"
+	listings[1].ID = "b"
+
+	result := NewValuationWithConfig(subject, listings, cfg).Estimate()
+
+	if result.Value != 600000 {
+		t.Errorf("Value = %v, want 600000", result.Value)
+	}
+	if result.Low != 500000 || result.High != 700000 {
+		t.Errorf("Range = %v-%v, want 500000-700000", result.Low, result.High)
+	}
+	if len(result.Comparables) != 2 {
+		t.Fatalf("got %d comparables, want 2", len(result.Comparables))
+	}
+	if result.Comparables[0].ID != "a" || result.Comparables[0].Weight != 1.0 {
+		t.Errorf("first comparable = %+v, want id a with weight 1", result.Comparables[0])
+	}
+}
+
+type querySource struct {
+	listings []models.Property
+	queries  []store.Query
+}
+
+func (s *querySource) Listings(q store.Query) ([]models.Property, error) {
+	s.queries = append(s.queries, q)
+	return store.New(s.listings).Query(q), nil
+}
+
+func TestNewValuationWithSource(t *testing.T) {
+	now := time.Now().Unix()
+	oneMonthAgo := now - (30 * 24 * 60 * 60)
+
+	cfg := &config.Config{MinSalesCount: 1}
+	cfg.CriteriaWeights.Status = 1.0
+	cfg.StatusScores.Sold = 1.0
+
+	subject := createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Active", now, 0, 0, 0)
+	src := &querySource{listings: []models.Property{
+		createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Closed", oneMonthAgo, oneMonthAgo, 500000, 500000),
+		createTestProperty("Norwalk", 2000, 4, 2.5, "Colonial", "Closed", oneMonthAgo, oneMonthAgo, 900000, 900000),
+		createTestProperty("Danbury", 4000, 4, 2.5, "Colonial", "Closed", oneMonthAgo, oneMonthAgo, 900000, 900000),
+	}}
+
+	valuation, err := NewValuationWithSource(subject, src, cfg)
+	if err != nil {
+		t.Fatalf("NewValuationWithSource() error = %v", err)
+	}
+
+	if len(src.queries) != 1 || src.queries[0].City != "Danbury" {
+		t.Fatalf("source queries = %+v, want one query for Danbury", src.queries)
+	}
+	if len(valuation.Listings) != 1 {
+		t.Errorf("got %d listings from the source, want 1", len(valuation.Listings))
+	}
+	if got := valuation.Calculate(); got != 500000 {
+		t.Errorf("Calculate() = %v, want 500000", got)
+	}
+}
+
+func TestValuation_SubjectFromListings(t *testing.T) {
+	now := time.Now().Unix()
+	oneMonthAgo := now - (30 * 24 * 60 * 60)
+	oneYearAgo := now - (365 * 24 * 60 * 60)
+
+	cfg := &config.Config{MinSalesCount: 1}
+	cfg.CriteriaWeights.Status = 1.0
+	cfg.StatusScores.Sold = 1.0
+	cfg.StatusScores.Active = 1.0
+
+	subject := createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Active", now, 0, 650000, 0)
+	subject.ID, subject.Address.Street, subject.Address.Zip = "s", "9 Thorncrest Ridge Unit Lot #52", "06810"
+	priorSale := createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Closed", oneYearAgo, oneYearAgo+1, 400000, 400000)
+	priorSale.ID, priorSale.Address.Street, priorSale.Address.Zip = "p", "9 THORNCREST RDG #52", "06810"
+	comp := createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Closed", oneMonthAgo, oneMonthAgo, 500000, 500000)
+	comp.ID, comp.Address.Street, comp.Address.Zip = "c", "12 Main St", "06810"
+
+	result := NewValuationWithConfig(subject, []models.Property{subject, priorSale, comp}, cfg).Estimate()
+
+	if len(result.Comparables) != 1 || result.Comparables[0].ID != "c" {
+		t.Fatalf("Comparables = %+v, want only c", result.Comparables)
+	}
+	diff, ratio := result.PriceGap(subject.ListPrice)
+	if diff != 150000 || ratio != 0.3 {
+		t.Errorf("PriceGap() = %v, %v, want 150000, 0.3", diff, ratio)
+	}
+	if diff, ratio := (Result{}).PriceGap(subject.ListPrice); diff != 0 || ratio != 0 {
+		t.Errorf("PriceGap() without an estimate = %v, %v, want 0, 0", diff, ratio)
+	}
+}
+
+func TestValuation_MarketSignals(t *testing.T) {
+	now := time.Now().Unix()
+	oneMonthAgo := now - (30 * 24 * 60 * 60)
+
+	cfg := &config.Config{MinSalesCount: 1}
+	cfg.CriteriaWeights.Status = 1.0
+	cfg.StatusScores.Active = 1.0
+	cfg.MarketSignals.DaysOnMarket = []config.CurvePoint{{At: 30, Factor: 1}, {At: 90, Factor: 0.5}}
+	cfg.MarketSignals.PriceCut = []config.CurvePoint{{At: 0, Factor: 1}, {At: 10, Factor: 0.5}}
+
+	fresh := createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Active", oneMonthAgo, 0, 500000, 0)
+	fresh.ID, fresh.DaysOnMarket = "fresh", 10
+	stale := createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Active", oneMonthAgo, 0, 600000, 0)
+	stale.ID, stale.DaysOnMarket = "stale", 120
+	cut := createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Active", oneMonthAgo, 0, 450000, 0)
+	cut.ID, cut.DaysOnMarket, cut.PreviousListPrice = "cut", 10, 500000
+
+	subject := createTestProperty("Danbury", 2000, 4, 2.5, "Colonial", "Active", now, 0, 0, 0)
+	result := NewValuationWithConfig(subject, []models.Property{fresh, stale, cut}, cfg).Estimate()
+
+	weights := map[string]float64{}
+	for _, c := range result.Comparables {
+		weights[c.ID] = c.Weight
+	}
+	if weights["fresh"] != 1 || weights["stale"] != 0.5 || weights["cut"] != 0.5 {
+		t.Errorf("weights = %v, want fresh 1, stale 0.5 and cut 0.5", weights)
+	}
+	if want := (500000 + 0.5*600000 + 0.5*450000) / 2; math.Abs(result.Value-want) > 1e-6 {
+		t.Errorf("Value = %v, want %v", result.Value, want)
+	}
+}
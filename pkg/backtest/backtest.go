@@ -0,0 +1,119 @@
+package backtest
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+	"github.com/krlosmederos/locqube-challenge/pkg/algorithm"
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+// Result holds the outcome of valuing a single closed sale
+type Result struct {
+	ID       string  `json:"id"`
+	Actual   float64 `json:"actual"`
+	Estimate float64 `json:"estimate"`
+	APE      float64 `json:"ape"`
+}
+
+// Metrics summarizes the accuracy of a backtest run
+type Metrics struct {
+	Count   int     `json:"count"`
+	Skipped int     `json:"skipped"`
+	MdAPE   float64 `json:"mdape"`
+	MAPE    float64 `json:"mape"`
+}
+
+type Report struct {
+	Results []Result `json:"results"`
+	Metrics Metrics  `json:"metrics"`
+}
+
+// Run values every closed sale in the listings as of its sale date against
+// the other listings known by then (leave-one-out) and compares the
+// estimate with the actual sale price
+func Run(cfg *config.Config, listings []models.Property) Report {
+	var report Report
+
+	for i, subject := range listings {
+		if !isBacktestable(subject) {
+			continue
+		}
+
+		valuation := algorithm.NewValuationWithConfig(subject, priorListings(listings, i), cfg)
+		valuation.AsOf = time.Unix(subject.StatusChangeTimestamp, 0)
+		estimate := valuation.Calculate()
+		if estimate == 0 {
+			report.Metrics.Skipped++
+			continue
+		}
+
+		report.Results = append(report.Results, Result{
+			ID:       subject.ID,
+			Actual:   subject.SalePrice,
+			Estimate: estimate,
+			APE:      math.Abs(estimate-subject.SalePrice) / subject.SalePrice,
+		})
+	}
+
+	report.Metrics.Count = len(report.Results)
+	report.Metrics.MdAPE, report.Metrics.MAPE = summarize(report.Results)
+
+	return report
+}
+
+func isBacktestable(p models.Property) bool {
+	return p.Status == "Closed" && p.SalePrice > 0 && p.Size > 0 && p.StatusChangeTimestamp > 0
+}
+
+// priorListings returns the listings other than the subject at index i that
+// were closed, or listed when not closed, at or before the subject's sale.
+// Other records of the subject's address, such as its relists, are left out.
+func priorListings(listings []models.Property, i int) []models.Property {
+	subject := listings[i]
+	soldAt := subject.StatusChangeTimestamp
+	key := address.Key(subject)
+
+	comps := make([]models.Property, 0, len(listings)-1)
+	for j, p := range listings {
+		if j == i || (key != "" && address.Key(p) == key) {
+			continue
+		}
+
+		date := p.ListingDate
+		if p.Status == "Closed" {
+			date = p.StatusChangeTimestamp
+		}
+		if date <= 0 || date > soldAt {
+			continue
+		}
+		comps = append(comps, p)
+	}
+	return comps
+}
+
+func summarize(results []Result) (median, mean float64) {
+	if len(results) == 0 {
+		return 0, 0
+	}
+
+	errors := make([]float64, len(results))
+	var sum float64
+	for i, r := range results {
+		errors[i] = r.APE
+		sum += r.APE
+	}
+	sort.Float64s(errors)
+
+	mid := len(errors) / 2
+	if len(errors)%2 == 0 {
+		median = (errors[mid-1] + errors[mid]) / 2
+	} else {
+		median = errors[mid]
+	}
+
+	return median, sum / float64(len(errors))
+}
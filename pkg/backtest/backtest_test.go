@@ -0,0 +1,152 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+func createTestConfig() *config.Config {
+	cfg := &config.Config{MinSalesCount: 3}
+	cfg.CriteriaWeights.PropertyType = 0.2
+	cfg.CriteriaWeights.Bedrooms = 0.05
+	cfg.CriteriaWeights.Bathrooms = 0.05
+	cfg.CriteriaWeights.Size = 0.1
+	cfg.CriteriaWeights.Recency = 0.5
+	cfg.CriteriaWeights.Status = 0.1
+	cfg.TimeScores.ThreeMonths = 1.0
+	cfg.TimeScores.SixMonths = 0.5
+	cfg.TimeScores.NineMonths = 0.25
+	cfg.StatusScores.Sold = 1.0
+	cfg.StatusScores.Pending = 0.6
+	cfg.StatusScores.Active = 0.4
+	return cfg
+}
+
+func createClosedSale(id string, salePrice float64, soldAt int64) models.Property {
+	return models.Property{
+		ID:                    id,
+		Address:               models.Address{City: "Danbury"},
+		Size:                  2000,
+		Beds:                  4,
+		Baths:                 models.Bathroom{Total: 2.5},
+		Style:                 "Colonial",
+		Status:                "Closed",
+		ListingDate:           soldAt,
+		StatusChangeTimestamp: soldAt,
+		ListPrice:             salePrice,
+		SalePrice:             salePrice,
+	}
+}
+
+func TestRun(t *testing.T) {
+	now := time.Now().Unix()
+	oneMonthAgo := now - (30 * 24 * 60 * 60)
+
+	tests := []struct {
+		name        string
+		listings    []models.Property
+		wantCount   int
+		wantSkipped int
+		wantMdAPE   float64
+	}{
+		{
+			name: "identical sales have no error",
+			listings: []models.Property{
+				createClosedSale("1", 600000, oneMonthAgo),
+				createClosedSale("2", 600000, oneMonthAgo),
+				createClosedSale("3", 600000, oneMonthAgo),
+			},
+			wantCount: 3,
+			wantMdAPE: 0,
+		},
+		{
+			name: "outlier sale",
+			listings: []models.Property{
+				createClosedSale("1", 500000, oneMonthAgo),
+				createClosedSale("2", 500000, oneMonthAgo),
+				createClosedSale("3", 1000000, oneMonthAgo),
+			},
+			wantCount: 3,
+			wantMdAPE: 0.5,
+		},
+		{
+			name: "sale without comparables is skipped",
+			listings: []models.Property{
+				createClosedSale("1", 600000, oneMonthAgo),
+			},
+			wantCount:   0,
+			wantSkipped: 1,
+			wantMdAPE:   0,
+		},
+		{
+			name: "active listings are not backtested",
+			listings: []models.Property{
+				createClosedSale("1", 600000, oneMonthAgo),
+				{
+					ID:          "2",
+					Address:     models.Address{City: "Danbury"},
+					Size:        2000,
+					Beds:        4,
+					Baths:       models.Bathroom{Total: 2.5},
+					Status:      "Active",
+					ListingDate: oneMonthAgo,
+					ListPrice:   600000,
+				},
+			},
+			wantCount: 1,
+			wantMdAPE: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Run(createTestConfig(), tt.listings)
+
+			if report.Metrics.Count != tt.wantCount {
+				t.Errorf("Count = %v, want %v", report.Metrics.Count, tt.wantCount)
+			}
+			if report.Metrics.Skipped != tt.wantSkipped {
+				t.Errorf("Skipped = %v, want %v", report.Metrics.Skipped, tt.wantSkipped)
+			}
+			if math.Abs(report.Metrics.MdAPE-tt.wantMdAPE) > 0.0001 {
+				t.Errorf("MdAPE = %v, want %v", report.Metrics.MdAPE, tt.wantMdAPE)
+			}
+		})
+	}
+}
+
+func TestRun_NoFutureComparables(t *testing.T) {
+	now := time.Now().Unix()
+	month := int64(30 * 24 * 60 * 60)
+
+	// the subject sold a year ago among sales of its market at the time,
+	// the later sales and the relist of the same house must not be used
+	subject := createClosedSale("subject", 500000, now-12*month)
+	subject.Address.Street, subject.Address.Zip = "12 Main St", "06810"
+	relist := createClosedSale("relist", 900000, now-month)
+	relist.Address.Street, relist.Address.Zip = "12 MAIN ST.", "06810"
+
+	listings := []models.Property{
+		subject,
+		createClosedSale("2", 500000, now-13*month),
+		createClosedSale("3", 500000, now-14*month),
+		createClosedSale("4", 1000000, now-month),
+		createClosedSale("5", 1000000, now-2*month),
+		relist,
+	}
+
+	report := Run(createTestConfig(), listings)
+
+	for _, r := range report.Results {
+		if r.ID == "subject" && r.Estimate != 500000 {
+			t.Errorf("subject estimate = %v, want 500000 from the sales before it", r.Estimate)
+		}
+	}
+	if len(report.Results) == 0 || report.Results[0].ID != "subject" {
+		t.Errorf("Results = %+v, want the subject valued", report.Results)
+	}
+}
@@ -0,0 +1,209 @@
+package batch
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/algorithm"
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+// Subject is a property to value, Err is set when the input record could not be read
+type Subject struct {
+	Index    int
+	Property models.Property
+	Err      error
+}
+
+// Result is the outcome of valuing one subject, Error is set instead of the
+// estimate when the subject could not be valued
+type Result struct {
+	Index       int     `json:"index"`
+	ID          string  `json:"id"`
+	Value       float64 `json:"value"`
+	Low         float64 `json:"low"`
+	High        float64 `json:"high"`
+	Profile     string  `json:"profile,omitempty"`
+	Comparables int     `json:"comparables"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// Summary counts the outcomes of a batch run
+type Summary struct {
+	Total         int           `json:"total"`
+	Valued        int           `json:"valued"`
+	NoComparables int           `json:"no_comparables"`
+	Failed        int           `json:"failed"`
+	Duration      time.Duration `json:"duration"`
+}
+
+// Runner values many subjects against the same listings with a bounded number of workers
+type Runner struct {
+	Config  *config.Config
+	Workers int
+	store   *store.Store
+}
+
+// NewRunner indexes the listings once so that every subject only looks at the
+// listings that can be comparable
+func NewRunner(cfg *config.Config, listings []models.Property, workers int) *Runner {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	return &Runner{
+		Config:  cfg,
+		Workers: workers,
+		store:   store.New(listings),
+	}
+}
+
+// reorderWindow is the number of subjects per worker that may be read ahead
+// of the next result to emit
+const reorderWindow = 4
+
+// job is a subject with its position in the input and its result once valued
+type job struct {
+	seq     int
+	subject Subject
+	result  Result
+}
+
+// Run values every subject received from the channel and passes the results
+// to emit in input order. At most reorderWindow subjects per worker are read
+// ahead of the next result, so a slow subject holds back the reading instead
+// of letting the later results pile up. If emit fails Run stops valuing,
+// drains the remaining subjects so that their sender is not blocked and
+// returns the error.
+func (r *Runner) Run(subjects <-chan Subject, emit func(Result) error) (Summary, error) {
+	start := time.Now()
+
+	jobs := make(chan job)
+	results := make(chan job, r.Workers)
+	window := make(chan struct{}, r.Workers*reorderWindow)
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < r.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				j.result = r.value(j.subject)
+				results <- j
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		seq := 0
+		for s := range subjects {
+			select {
+			case window <- struct{}{}:
+			case <-done:
+				drain(subjects)
+				return
+			}
+
+			select {
+			case jobs <- job{seq: seq, subject: s}:
+				seq++
+			case <-done:
+				drain(subjects)
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var summary Summary
+	var emitErr error
+	pending := map[int]Result{}
+	next := 0
+
+	for j := range results {
+		if emitErr != nil {
+			continue
+		}
+
+		pending[j.seq] = j.result
+		for {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-window
+
+			summary.add(ready)
+			if err := emit(ready); err != nil {
+				emitErr = err
+				close(done)
+				break
+			}
+		}
+	}
+
+	summary.Duration = time.Since(start)
+	return summary, emitErr
+}
+
+// drain discards the subjects left in the channel until it is closed
+func drain(subjects <-chan Subject) {
+	for range subjects {
+	}
+}
+
+func (r *Runner) value(s Subject) Result {
+	result := Result{Index: s.Index, ID: s.Property.ID}
+
+	if s.Err != nil {
+		result.Error = s.Err.Error()
+		return result
+	}
+	if err := validateSubject(s.Property); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	estimate := algorithm.NewValuationWithStore(s.Property, r.store, r.Config).Estimate()
+
+	result.Value = estimate.Value
+	result.Low = estimate.Low
+	result.High = estimate.High
+	result.Profile = estimate.Profile
+	result.Comparables = len(estimate.Comparables)
+	return result
+}
+
+func (s *Summary) add(r Result) {
+	s.Total++
+	switch {
+	case r.Error != "":
+		s.Failed++
+	case r.Value == 0:
+		s.NoComparables++
+	default:
+		s.Valued++
+	}
+}
+
+func validateSubject(p models.Property) error {
+	if p.Address.City == "" {
+		return fmt.Errorf("address.city is required")
+	}
+	if p.Size <= 0 {
+		return fmt.Errorf("size must be greater than 0")
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+func createTestConfig() *config.Config {
+	cfg := config.Defaults()
+	cfg.MinSalesCount = 1
+	return cfg
+}
+
+func createTestProperty(id, city string, size float64, price float64) models.Property {
+	oneMonthAgo := time.Now().Unix() - (30 * 24 * 60 * 60)
+	return models.Property{
+		ID:                    id,
+		Address:               models.Address{City: city},
+		Size:                  size,
+		Beds:                  4,
+		Baths:                 models.Bathroom{Total: 2.5},
+		Style:                 "Colonial",
+		Status:                "Closed",
+		ListingDate:           oneMonthAgo,
+		StatusChangeTimestamp: oneMonthAgo,
+		ListPrice:             price,
+		SalePrice:             price,
+	}
+}
+
+func sendSubjects(subjects []Subject) <-chan Subject {
+	ch := make(chan Subject)
+	go func() {
+		defer close(ch)
+		for _, s := range subjects {
+			ch <- s
+		}
+	}()
+	return ch
+}
+
+func TestRunner_Run(t *testing.T) {
+	listings := []models.Property{
+		createTestProperty("d1", "Danbury", 2000, 600000),
+		createTestProperty("d2", "Danbury", 2000, 600000),
+		createTestProperty("n1", "Norwalk", 2000, 900000),
+	}
+
+	var subjects []Subject
+	for i := 0; i < 50; i++ {
+		city := "Danbury"
+		if i%2 == 1 {
+			city = "Norwalk"
+		}
+		subjects = append(subjects, Subject{Index: i, Property: createTestProperty(fmt.Sprint(i), city, 2000, 0)})
+	}
+	subjects = append(subjects,
+		Subject{Index: 50, Property: createTestProperty("stamford", "Stamford", 2000, 0)},
+		Subject{Index: 51, Property: createTestProperty("no-size", "Danbury", 0, 0)},
+		Subject{Index: 52, Err: errors.New("line 54: invalid JSON")},
+	)
+
+	runner := NewRunner(createTestConfig(), listings, 4)
+
+	var results []Result
+	summary, err := runner.Run(sendSubjects(subjects), func(r Result) error {
+		results = append(results, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	if len(results) != len(subjects) {
+		t.Fatalf("got %d results, want %d", len(results), len(subjects))
+	}
+	for i, r := range results {
+		if r.Index != i {
+			t.Fatalf("result %d has index %d, want results in input order", i, r.Index)
+		}
+	}
+
+	if results[0].Value != 600000 || results[0].Comparables != 2 {
+		t.Errorf("Danbury result = %+v, want 600000 from 2 comparables", results[0])
+	}
+	if results[1].Value != 900000 || results[1].Comparables != 1 {
+		t.Errorf("Norwalk result = %+v, want 900000 from 1 comparable", results[1])
+	}
+	if results[51].Error == "" || results[52].Error == "" {
+		t.Error("invalid subjects should have an error")
+	}
+
+	want := Summary{Total: 53, Valued: 50, NoComparables: 1, Failed: 2}
+	summary.Duration = 0
+	if summary != want {
+		t.Errorf("Summary = %+v, want %+v", summary, want)
+	}
+}
+
+func TestRunner_RunStopsOnEmitError(t *testing.T) {
+	var subjects []Subject
+	for i := 0; i < 100; i++ {
+		subjects = append(subjects, Subject{Index: i, Property: createTestProperty(fmt.Sprint(i), "Danbury", 2000, 0)})
+	}
+
+	runner := NewRunner(createTestConfig(), nil, 2)
+	emitted := 0
+	_, err := runner.Run(sendSubjects(subjects), func(r Result) error {
+		emitted++
+		if emitted == 3 {
+			return errors.New("disk full")
+		}
+		return nil
+	})
+
+	if err == nil || err.Error() != "disk full" {
+		t.Errorf("Run() error = %v, want disk full", err)
+	}
+	if emitted != 3 {
+		t.Errorf("emit called %d times, want 3", emitted)
+	}
+}
+
+func TestRunner_RunDrainsSubjectsOnEmitError(t *testing.T) {
+	subjects := make(chan Subject)
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		defer close(subjects)
+		for i := 0; i < 1000; i++ {
+			subjects <- Subject{Index: i, Property: createTestProperty(fmt.Sprint(i), "Danbury", 2000, 0)}
+		}
+	}()
+
+	runner := NewRunner(createTestConfig(), nil, 2)
+	_, err := runner.Run(subjects, func(r Result) error {
+		return errors.New("disk full")
+	})
+	if err == nil {
+		t.Fatal("Run() expected an error")
+	}
+
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Error("the sender is still blocked after Run() returned")
+	}
+}
+
+func TestRunner_RunIndexGaps(t *testing.T) {
+	var subjects []Subject
+	for _, index := range []int{3, 7, 8, 20} {
+		subjects = append(subjects, Subject{Index: index, Property: createTestProperty(fmt.Sprint(index), "Danbury", 2000, 0)})
+	}
+
+	runner := NewRunner(createTestConfig(), nil, 2)
+	var got []int
+	if _, err := runner.Run(sendSubjects(subjects), func(r Result) error {
+		got = append(got, r.Index)
+		return nil
+	}); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	if fmt.Sprint(got) != "[3 7 8 20]" {
+		t.Errorf("emitted indexes %v, want [3 7 8 20] in input order", got)
+	}
+}
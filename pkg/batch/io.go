@@ -0,0 +1,204 @@
+package batch
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+const maxLineBytes = 4 << 20
+
+// ReadJSONL reads one models.Property per line and sends it to out, a line
+// that cannot be decoded is sent as a subject with an error. out is closed
+// when the reader is exhausted.
+func ReadJSONL(r io.Reader, out chan<- Subject) error {
+	defer close(out)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+
+	index := 0
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		subject := Subject{Index: index}
+		if err := json.Unmarshal([]byte(text), &subject.Property); err != nil {
+			subject.Err = fmt.Errorf("line %d: %v", line, err)
+		}
+		out <- subject
+		index++
+	}
+
+	return scanner.Err()
+}
+
+// csvColumns maps the supported CSV headers to the property field they set
+var csvColumns = map[string]func(p *models.Property, value string) error{
+	"id":            func(p *models.Property, v string) error { p.ID = v; return nil },
+	"street":        func(p *models.Property, v string) error { p.Address.Street = v; return nil },
+	"city":          func(p *models.Property, v string) error { p.Address.City = v; return nil },
+	"state":         func(p *models.Property, v string) error { p.Address.State = v; return nil },
+	"zip":           func(p *models.Property, v string) error { p.Address.Zip = v; return nil },
+	"county":        func(p *models.Property, v string) error { p.County = v; return nil },
+	"subdivision":   func(p *models.Property, v string) error { p.Subdivision = v; return nil },
+	"elementary":    func(p *models.Property, v string) error { p.Schools.Elementary = v; return nil },
+	"middle":        func(p *models.Property, v string) error { p.Schools.Middle = v; return nil },
+	"high":          func(p *models.Property, v string) error { p.Schools.High = v; return nil },
+	"district":      func(p *models.Property, v string) error { p.Schools.District = v; return nil },
+	"style":         func(p *models.Property, v string) error { p.Style = v; return nil },
+	"property_type": func(p *models.Property, v string) error { p.PropertyType = v; return nil },
+	"size":          func(p *models.Property, v string) error { return parseFloat(&p.Size, v) },
+	"baths":         func(p *models.Property, v string) error { return parseFloat(&p.Baths.Total, v) },
+	"beds":          func(p *models.Property, v string) error { return parseInt(&p.Beds, v) },
+	"baths_full":    func(p *models.Property, v string) error { return parseInt(&p.Baths.Full, v) },
+	"baths_half":    func(p *models.Property, v string) error { return parseInt(&p.Baths.Half, v) },
+	"year_built":    func(p *models.Property, v string) error { return parseInt(&p.YearBuilt, v) },
+}
+
+// ReadCSV reads subjects from a CSV file with a header row, the supported
+// columns are id, street, city, state, zip, county, subdivision, elementary,
+// middle, high, district, style, property_type, size, beds, baths,
+// baths_full, baths_half and year_built, other columns are ignored. A row
+// with an invalid value is sent as a subject with an error. out is closed
+// when the reader is exhausted.
+func ReadCSV(r io.Reader, out chan<- Subject) error {
+	defer close(out)
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %v", err)
+	}
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	for index, line := 0, 2; ; index, line = index+1, line+1 {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+
+		subject := Subject{Index: index}
+		for i, value := range record {
+			if i >= len(header) {
+				break
+			}
+			set, ok := csvColumns[header[i]]
+			if !ok || strings.TrimSpace(value) == "" {
+				continue
+			}
+			if err := set(&subject.Property, strings.TrimSpace(value)); err != nil {
+				subject.Err = fmt.Errorf("line %d: column %s: %v", line, header[i], err)
+				break
+			}
+		}
+		out <- subject
+	}
+}
+
+func parseFloat(dst *float64, value string) error {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", value)
+	}
+	*dst = v
+	return nil
+}
+
+func parseInt(dst *int, value string) error {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", value)
+	}
+	*dst = v
+	return nil
+}
+
+// Writer streams batch results
+type Writer interface {
+	Write(Result) error
+	Flush() error
+}
+
+type jsonlWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+// NewJSONLWriter writes one JSON result per line
+func NewJSONLWriter(w io.Writer) Writer {
+	bw := bufio.NewWriter(w)
+	return &jsonlWriter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+func (j *jsonlWriter) Write(r Result) error {
+	return j.enc.Encode(r)
+}
+
+func (j *jsonlWriter) Flush() error {
+	return j.w.Flush()
+}
+
+var csvHeader = []string{"index", "id", "value", "low", "high", "profile", "comparables", "error"}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+// NewCSVWriter writes one CSV row per result after a header row
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Write(r Result) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	return c.w.Write([]string{
+		strconv.Itoa(r.Index),
+		r.ID,
+		formatPrice(r.Value),
+		formatPrice(r.Low),
+		formatPrice(r.High),
+		r.Profile,
+		strconv.Itoa(r.Comparables),
+		r.Error,
+	})
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+	return c.w.Write(csvHeader)
+}
+
+func (c *csvWriter) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func formatPrice(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
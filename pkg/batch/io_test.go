@@ -0,0 +1,123 @@
+package batch
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func collect(t *testing.T, read func(chan<- Subject) error) []Subject {
+	t.Helper()
+
+	ch := make(chan Subject)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- read(ch)
+	}()
+
+	var subjects []Subject
+	for s := range ch {
+		subjects = append(subjects, s)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	return subjects
+}
+
+func TestReadJSONL(t *testing.T) {
+	input := `{"id": "1", "address": {"city": "Danbury"}, "size": 2000}
+
+{"id": "2", "size": "large"}
+{"id": "3", "address": {"city": "Norwalk"}, "size": 1800, "beds": 3}
+`
+	subjects := collect(t, func(ch chan<- Subject) error {
+		return ReadJSONL(strings.NewReader(input), ch)
+	})
+
+	if len(subjects) != 3 {
+		t.Fatalf("got %d subjects, want 3", len(subjects))
+	}
+	for i, s := range subjects {
+		if s.Index != i {
+			t.Errorf("subject %d has index %d", i, s.Index)
+		}
+	}
+	if subjects[0].Property.Address.City != "Danbury" || subjects[0].Err != nil {
+		t.Errorf("first subject = %+v", subjects[0])
+	}
+	if subjects[1].Err == nil || !strings.Contains(subjects[1].Err.Error(), "line 3") {
+		t.Errorf("second subject error = %v, want an error on line 3", subjects[1].Err)
+	}
+	if subjects[2].Property.Beds != 3 {
+		t.Errorf("third subject beds = %v, want 3", subjects[2].Property.Beds)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	input := "ID,City,State,Size,Beds,Baths,Notes\n" +
+		"a,Danbury,CT,2000,4,2.5,corner lot\n" +
+		"b,Danbury,CT,big,4,2.5,\n"
+
+	subjects := collect(t, func(ch chan<- Subject) error {
+		return ReadCSV(strings.NewReader(input), ch)
+	})
+
+	if len(subjects) != 2 {
+		t.Fatalf("got %d subjects, want 2", len(subjects))
+	}
+
+	first := subjects[0].Property
+	if first.ID != "a" || first.Address.City != "Danbury" || first.Size != 2000 || first.Beds != 4 || first.Baths.Total != 2.5 {
+		t.Errorf("first subject = %+v", first)
+	}
+	if subjects[1].Err == nil || !strings.Contains(subjects[1].Err.Error(), "column size") {
+		t.Errorf("second subject error = %v, want an error on column size", subjects[1].Err)
+	}
+}
+
+func TestWriters(t *testing.T) {
+	results := []Result{
+		{Index: 0, ID: "a", Value: 600000, Low: 550000, High: 650000, Profile: "default", Comparables: 3},
+		{Index: 1, ID: "b", Error: "size must be greater than 0"},
+	}
+
+	tests := []struct {
+		name      string
+		newWriter func(*bytes.Buffer) Writer
+		want      string
+	}{
+		{
+			name:      "jsonl",
+			newWriter: func(b *bytes.Buffer) Writer { return NewJSONLWriter(b) },
+			want: `{"index":0,"id":"a","value":600000,"low":550000,"high":650000,"profile":"default","comparables":3}` + "\n" +
+				`{"index":1,"id":"b","value":0,"low":0,"high":0,"comparables":0,"error":"size must be greater than 0"}` + "\n",
+		},
+		{
+			name:      "csv",
+			newWriter: func(b *bytes.Buffer) Writer { return NewCSVWriter(b) },
+			want: "index,id,value,low,high,profile,comparables,error\n" +
+				"0,a,600000.00,550000.00,650000.00,default,3,\n" +
+				"1,b,0.00,0.00,0.00,,0,size must be greater than 0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := tt.newWriter(&buf)
+			for _, r := range results {
+				if err := w.Write(r); err != nil {
+					t.Fatalf("Write() failed: %v", err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush() failed: %v", err)
+			}
+
+			if buf.String() != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
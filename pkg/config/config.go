@@ -2,9 +2,15 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
 )
 
 type Config struct {
@@ -15,6 +21,7 @@ type Config struct {
 		Size         float64 `json:"size"`
 		Recency      float64 `json:"recency"`
 		Status       float64 `json:"status"`
+		Neighborhood float64 `json:"neighborhood"`
 	} `json:"criteria_weights"`
 
 	TimeScores struct {
@@ -30,46 +37,310 @@ type Config struct {
 	} `json:"status_scores"`
 
 	MinSalesCount int `json:"min_sales_count"`
+
+	// ValuationType selects the listings and prices valued, sales or the
+	// monthly rents of lease listings
+	ValuationType string `json:"valuation_type"`
+
+	// Rental holds the settings of the rental yield estimates
+	Rental struct {
+		ExpenseRatio float64 `json:"expense_ratio"`
+	} `json:"rental"`
+
+	// SchoolDistrict restricts the comparables to the subject's school
+	// district when at least MinSales closed sales are in it, 0 disables it
+	SchoolDistrict struct {
+		MinSales int `json:"min_sales"`
+	} `json:"school_district"`
+
+	// DataQuality selects what valuations do with listings failing validation
+	DataQuality struct {
+		Invalid string `json:"invalid"`
+	} `json:"data_quality"`
+
+	// SaleToList prices the active and pending comparables at their list
+	// price times the sale-to-list ratio of the closed comparables
+	SaleToList struct {
+		Mode     string `json:"mode"`
+		MinSales int    `json:"min_sales"`
+	} `json:"sale_to_list"`
+
+	// MarketSignals scales down the weight of the unsold comparables that
+	// have been on the market long or had their price cut recently
+	MarketSignals struct {
+		DaysOnMarket []CurvePoint `json:"days_on_market,omitempty"`
+		PriceCut     []CurvePoint `json:"price_cut,omitempty"`
+		PriceCutAge  []CurvePoint `json:"price_cut_age,omitempty"`
+	} `json:"market_signals"`
+
+	// Address lists the municipalities feeds also write with a designation,
+	// such as "Danbury Town" for Danbury, so that both names are one city.
+	// Profiles and request settings cannot change it.
+	Address struct {
+		DesignatedCities []string `json:"designated_cities,omitempty"`
+	} `json:"address"`
+
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+
+	// overrides are the environment and flag values the configuration was
+	// loaded with, they are applied again on top of the settings of a profile
+	overrides []override
+}
+
+// Values of valuation_type. Sale values the sale price from the listings
+// that are not for rent, rent values the monthly rent from the lease
+// listings. An empty value is the same as sale.
+const (
+	ValuationSale = "sale"
+	ValuationRent = "rent"
+)
+
+// Values of data_quality.invalid. Keep uses every listing as is, exclude
+// drops the listings with validation errors and repair fixes what can be
+// derived from the other fields before dropping the listings still invalid.
+// An empty value is the same as keep.
+const (
+	DataQualityKeep    = "keep"
+	DataQualityExclude = "exclude"
+	DataQualityRepair  = "repair"
+)
+
+// CurvePoint is a point of a piecewise linear curve giving the weight factor
+// of a comparable at a value, such as its days on market
+type CurvePoint struct {
+	At     float64 `json:"at"`
+	Factor float64 `json:"factor"`
+}
+
+// Values of sale_to_list.mode. Off prices every comparable at its list price
+// until it sells, list computes the ratio against the current list price and
+// original against the previous list price when it was higher, so that price
+// reductions count in the discount. An empty value is the same as off.
+const (
+	SaleToListOff      = "off"
+	SaleToListList     = "list"
+	SaleToListOriginal = "original"
+)
+
+// weightsSumTolerance is the allowed deviation of the criteria weights sum from 1
+const weightsSumTolerance = 0.001
+
+// FieldError describes a problem with a single configuration field
+type FieldError struct {
+	Field   string
+	Problem string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Problem
+}
+
+// ValidationError holds every problem found while validating a configuration
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	problems := make([]string, len(e))
+	for i, fe := range e {
+		problems[i] = fe.Error()
+	}
+	return "invalid configuration: " + strings.Join(problems, "; ")
 }
 
 var (
-	config *Config
-	once   sync.Once
+	config  *Config
+	loadErr error
+	once    sync.Once
 )
 
-// LoadConfig loads the configuration from the JSON file
+// DefaultPath is the location of the configuration file used by LoadConfig,
+// relative to the working directory
+var DefaultPath = filepath.Join("config", "application.json")
+
+// LoadConfig loads the global configuration from DefaultPath, the file is
+// read only once and later calls return the same instance
 func LoadConfig() (*Config, error) {
 	once.Do(func() {
-		config = &Config{}
+		config, loadErr = Load(DefaultPath)
+	})
+
+	return config, loadErr
+}
+
+// Load reads and validates the configuration file at the given path,
+// every call returns a new independent configuration
+func Load(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse decodes and validates a JSON configuration from the reader, the
+// designated cities of the address matching are set from it
+func Parse(r io.Reader) (*Config, error) {
+	cfg := &Config{}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %v", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	address.SetDesignatedCities(cfg.Address.DesignatedCities)
 
-		configPath := filepath.Join("config", "application.json")
+	return cfg, nil
+}
+
+// Validate checks the configuration values and returns a ValidationError
+// listing every invalid field, or nil if the configuration is valid
+func (c *Config) Validate() error {
+	var errs ValidationError
 
-		file, err := os.ReadFile(configPath)
-		if err != nil {
-			panic("Failed to read config file: " + err.Error())
+	weights := []struct {
+		field string
+		value float64
+	}{
+		{"criteria_weights.property_type", c.CriteriaWeights.PropertyType},
+		{"criteria_weights.bedrooms", c.CriteriaWeights.Bedrooms},
+		{"criteria_weights.bathrooms", c.CriteriaWeights.Bathrooms},
+		{"criteria_weights.size", c.CriteriaWeights.Size},
+		{"criteria_weights.recency", c.CriteriaWeights.Recency},
+		{"criteria_weights.status", c.CriteriaWeights.Status},
+		{"criteria_weights.neighborhood", c.CriteriaWeights.Neighborhood},
+	}
+
+	var sum float64
+	for _, w := range weights {
+		if w.value < 0 {
+			errs = append(errs, FieldError{w.field, fmt.Sprintf("must not be negative, got %v", w.value)})
 		}
+		sum += w.value
+	}
+	if math.Abs(sum-1) > weightsSumTolerance {
+		errs = append(errs, FieldError{"criteria_weights", fmt.Sprintf("must sum to 1, got %v", sum)})
+	}
+
+	scores := []struct {
+		field string
+		value float64
+	}{
+		{"time_scores.three_months", c.TimeScores.ThreeMonths},
+		{"time_scores.six_months", c.TimeScores.SixMonths},
+		{"time_scores.nine_months", c.TimeScores.NineMonths},
+		{"status_scores.sold", c.StatusScores.Sold},
+		{"status_scores.pending", c.StatusScores.Pending},
+		{"status_scores.active", c.StatusScores.Active},
+	}
 
-		if err := json.Unmarshal(file, config); err != nil {
-			panic("Failed to parse config file: " + err.Error())
+	for _, s := range scores {
+		if s.value < 0 || s.value > 1 {
+			errs = append(errs, FieldError{s.field, fmt.Sprintf("must be between 0 and 1, got %v", s.value)})
 		}
-	})
+	}
 
-	return config, nil
+	// an older sale or a listing further from closing never scores higher,
+	// scores out of range are reported above only
+	order := []struct {
+		field, above string
+		value, limit float64
+	}{
+		{"time_scores.six_months", "three_months", c.TimeScores.SixMonths, c.TimeScores.ThreeMonths},
+		{"time_scores.nine_months", "six_months", c.TimeScores.NineMonths, c.TimeScores.SixMonths},
+		{"status_scores.pending", "sold", c.StatusScores.Pending, c.StatusScores.Sold},
+		{"status_scores.active", "pending", c.StatusScores.Active, c.StatusScores.Pending},
+	}
+	for _, o := range order {
+		if o.value > o.limit && o.value <= 1 && o.limit >= 0 {
+			errs = append(errs, FieldError{o.field, fmt.Sprintf("must not be greater than %s (%v), got %v", o.above, o.limit, o.value)})
+		}
+	}
+
+	if c.MinSalesCount < 1 {
+		errs = append(errs, FieldError{"min_sales_count", fmt.Sprintf("must be at least 1, got %v", c.MinSalesCount)})
+	}
+
+	if c.SchoolDistrict.MinSales < 0 {
+		errs = append(errs, FieldError{"school_district.min_sales", fmt.Sprintf("must not be negative, got %v", c.SchoolDistrict.MinSales)})
+	}
+
+	switch c.ValuationType {
+	case "", ValuationSale, ValuationRent:
+	default:
+		errs = append(errs, FieldError{"valuation_type", fmt.Sprintf("must be sale or rent, got %q", c.ValuationType)})
+	}
+	if c.Rental.ExpenseRatio < 0 || c.Rental.ExpenseRatio >= 1 {
+		errs = append(errs, FieldError{"rental.expense_ratio", fmt.Sprintf("must be at least 0 and below 1, got %v", c.Rental.ExpenseRatio)})
+	}
+
+	switch c.DataQuality.Invalid {
+	case "", DataQualityKeep, DataQualityExclude, DataQualityRepair:
+	default:
+		errs = append(errs, FieldError{"data_quality.invalid", fmt.Sprintf("must be keep, exclude or repair, got %q", c.DataQuality.Invalid)})
+	}
+
+	switch c.SaleToList.Mode {
+	case "", SaleToListOff, SaleToListList, SaleToListOriginal:
+	default:
+		errs = append(errs, FieldError{"sale_to_list.mode", fmt.Sprintf("must be off, list or original, got %q", c.SaleToList.Mode)})
+	}
+	if c.SaleToList.MinSales < 0 {
+		errs = append(errs, FieldError{"sale_to_list.min_sales", fmt.Sprintf("must not be negative, got %v", c.SaleToList.MinSales)})
+	}
+
+	errs = append(errs, validateCurve("market_signals.days_on_market", c.MarketSignals.DaysOnMarket)...)
+	errs = append(errs, validateCurve("market_signals.price_cut", c.MarketSignals.PriceCut)...)
+	errs = append(errs, validateCurve("market_signals.price_cut_age", c.MarketSignals.PriceCutAge)...)
+
+	for i, name := range c.Address.DesignatedCities {
+		if address.City(name) == "" {
+			errs = append(errs, FieldError{fmt.Sprintf("address.designated_cities[%d]", i), "must not be empty"})
+		}
+	}
+
+	errs = append(errs, c.validateProfiles()...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
 
-// GetConfig returns the current configuration or loads it if it's not loaded
-func GetConfig() *Config {
-	if config == nil {
-		_, err := LoadConfig()
-		if err != nil {
-			panic("Failed to load config: " + err.Error())
+// validateCurve checks that the points of a curve are in increasing order
+// of a non-negative value and that their factors are between 0 and 1
+func validateCurve(field string, curve []CurvePoint) ValidationError {
+	var errs ValidationError
+	for i, p := range curve {
+		if p.At < 0 {
+			errs = append(errs, FieldError{fmt.Sprintf("%s[%d].at", field, i), fmt.Sprintf("must not be negative, got %v", p.At)})
+		}
+		if i > 0 && p.At <= curve[i-1].At {
+			errs = append(errs, FieldError{fmt.Sprintf("%s[%d].at", field, i), fmt.Sprintf("must be greater than the previous point, got %v", p.At)})
+		}
+		if p.Factor < 0 || p.Factor > 1 {
+			errs = append(errs, FieldError{fmt.Sprintf("%s[%d].factor", field, i), fmt.Sprintf("must be between 0 and 1, got %v", p.Factor)})
 		}
 	}
-	return config
+	return errs
+}
+
+// GetConfig returns the global configuration or loads it if it's not loaded,
+// it panics if the configuration cannot be loaded
+func GetConfig() *Config {
+	cfg, err := LoadConfig()
+	if err != nil {
+		panic("Failed to load config: " + err.Error())
+	}
+	return cfg
 }
 
 // ResetForTesting resets the config singleton for testing purposes
 func ResetForTesting() {
 	config = nil
+	loadErr = nil
 	once = sync.Once{}
 }
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -51,6 +54,7 @@ func TestLoadConfig(t *testing.T) {
 			Size         float64 `json:"size"`
 			Recency      float64 `json:"recency"`
 			Status       float64 `json:"status"`
+			Neighborhood float64 `json:"neighborhood"`
 		}{
 			PropertyType: 0.3,
 			Bedrooms:     0.05,
@@ -184,6 +188,7 @@ func TestConfigWeightsSum(t *testing.T) {
 			Size         float64 `json:"size"`
 			Recency      float64 `json:"recency"`
 			Status       float64 `json:"status"`
+			Neighborhood float64 `json:"neighborhood"`
 		}{
 			PropertyType: 0.3,
 			Bedrooms:     0.05,
@@ -205,3 +210,297 @@ func TestConfigWeightsSum(t *testing.T) {
 		t.Errorf("Criteria weights sum to %v, expected 1.0", sum)
 	}
 }
+
+func validTestConfig() *Config {
+	cfg := &Config{MinSalesCount: 3}
+	cfg.CriteriaWeights.PropertyType = 0.1
+	cfg.CriteriaWeights.Bedrooms = 0.05
+	cfg.CriteriaWeights.Bathrooms = 0.05
+	cfg.CriteriaWeights.Size = 0.2
+	cfg.CriteriaWeights.Recency = 0.5
+	cfg.CriteriaWeights.Status = 0.1
+	cfg.TimeScores.ThreeMonths = 1.0
+	cfg.TimeScores.SixMonths = 0.5
+	cfg.TimeScores.NineMonths = 0.25
+	cfg.StatusScores.Sold = 1.0
+	cfg.StatusScores.Pending = 0.6
+	cfg.StatusScores.Active = 0.4
+	return cfg
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(cfg *Config)
+		wantFields []string
+	}{
+		{
+			name:       "valid config",
+			modify:     func(cfg *Config) {},
+			wantFields: nil,
+		},
+		{
+			name: "negative weight",
+			modify: func(cfg *Config) {
+				cfg.CriteriaWeights.Size = -0.2
+				cfg.CriteriaWeights.Recency = 0.9
+			},
+			wantFields: []string{"criteria_weights.size"},
+		},
+		{
+			name: "weights do not sum to 1",
+			modify: func(cfg *Config) {
+				cfg.CriteriaWeights.Recency = 0.8
+			},
+			wantFields: []string{"criteria_weights"},
+		},
+		{
+			name: "score out of range",
+			modify: func(cfg *Config) {
+				cfg.TimeScores.SixMonths = 1.5
+				cfg.StatusScores.Active = -0.1
+			},
+			wantFields: []string{"time_scores.six_months", "status_scores.active"},
+		},
+		{
+			name: "scores out of order",
+			modify: func(cfg *Config) {
+				cfg.TimeScores.SixMonths = 0.2
+				cfg.TimeScores.NineMonths = 0.25
+				cfg.StatusScores.Pending = 1
+				cfg.StatusScores.Sold = 0.9
+			},
+			wantFields: []string{"time_scores.nine_months", "status_scores.pending"},
+		},
+		{
+			name: "empty designated city",
+			modify: func(cfg *Config) {
+				cfg.Address.DesignatedCities = []string{"Danbury", " "}
+			},
+			wantFields: []string{"address.designated_cities[1]"},
+		},
+		{
+			name: "zero min sales count",
+			modify: func(cfg *Config) {
+				cfg.MinSalesCount = 0
+			},
+			wantFields: []string{"min_sales_count"},
+		},
+		{
+			name: "data quality repair",
+			modify: func(cfg *Config) {
+				cfg.DataQuality.Invalid = DataQualityRepair
+			},
+			wantFields: nil,
+		},
+		{
+			name: "unknown data quality action",
+			modify: func(cfg *Config) {
+				cfg.DataQuality.Invalid = "drop"
+			},
+			wantFields: []string{"data_quality.invalid"},
+		},
+		{
+			name: "sale-to-list ratio against the original price",
+			modify: func(cfg *Config) {
+				cfg.SaleToList.Mode = SaleToListOriginal
+				cfg.SaleToList.MinSales = 5
+			},
+			wantFields: nil,
+		},
+		{
+			name: "invalid sale-to-list settings",
+			modify: func(cfg *Config) {
+				cfg.SaleToList.Mode = "median"
+				cfg.SaleToList.MinSales = -1
+			},
+			wantFields: []string{"sale_to_list.mode", "sale_to_list.min_sales"},
+		},
+		{
+			name: "rent valuation",
+			modify: func(cfg *Config) {
+				cfg.ValuationType = ValuationRent
+				cfg.Rental.ExpenseRatio = 0.35
+			},
+			wantFields: nil,
+		},
+		{
+			name: "neighborhood weight and school district",
+			modify: func(cfg *Config) {
+				cfg.CriteriaWeights.Recency -= 0.1
+				cfg.CriteriaWeights.Neighborhood = 0.1
+				cfg.SchoolDistrict.MinSales = 3
+			},
+			wantFields: nil,
+		},
+		{
+			name: "invalid neighborhood weight and school district",
+			modify: func(cfg *Config) {
+				cfg.CriteriaWeights.Neighborhood = -0.1
+				cfg.SchoolDistrict.MinSales = -1
+			},
+			wantFields: []string{"criteria_weights.neighborhood", "criteria_weights", "school_district.min_sales"},
+		},
+		{
+			name: "invalid rental settings",
+			modify: func(cfg *Config) {
+				cfg.ValuationType = "lease"
+				cfg.Rental.ExpenseRatio = 1
+			},
+			wantFields: []string{"valuation_type", "rental.expense_ratio"},
+		},
+		{
+			name: "market signal curves",
+			modify: func(cfg *Config) {
+				cfg.MarketSignals.DaysOnMarket = []CurvePoint{{At: 30, Factor: 1}, {At: 180, Factor: 0.5}}
+				cfg.MarketSignals.PriceCut = []CurvePoint{{At: 0, Factor: 1}}
+				cfg.MarketSignals.PriceCutAge = []CurvePoint{{At: 30, Factor: 1}, {At: 120, Factor: 0}}
+			},
+			wantFields: nil,
+		},
+		{
+			name: "invalid market signal curves",
+			modify: func(cfg *Config) {
+				cfg.MarketSignals.DaysOnMarket = []CurvePoint{{At: 90, Factor: 1}, {At: 30, Factor: 0.5}}
+				cfg.MarketSignals.PriceCut = []CurvePoint{{At: -1, Factor: 1.5}}
+				cfg.MarketSignals.PriceCutAge = []CurvePoint{{At: 30, Factor: -0.5}}
+			},
+			wantFields: []string{"market_signals.days_on_market[1].at", "market_signals.price_cut[0].at", "market_signals.price_cut[0].factor", "market_signals.price_cut_age[0].factor"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+
+			validationErr, ok := err.(ValidationError)
+			if !ok {
+				t.Fatalf("Validate() error = %v, want ValidationError", err)
+			}
+
+			if len(validationErr) != len(tt.wantFields) {
+				t.Fatalf("Validate() returned %d errors, want %d: %v", len(validationErr), len(tt.wantFields), err)
+			}
+			for i, field := range tt.wantFields {
+				if validationErr[i].Field != field {
+					t.Errorf("error %d field = %v, want %v", i, validationErr[i].Field, field)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "missing file",
+			wantErr: "failed to read config file",
+		},
+		{
+			name:    "invalid json",
+			content: `{"criteria_weights": `,
+			wantErr: "failed to parse config file",
+		},
+		{
+			name:    "unknown key",
+			content: `{"min_sales_count": 3, "max_sales_count": 10}`,
+			wantErr: `unknown field "max_sales_count"`,
+		},
+		{
+			name:    "invalid values",
+			content: `{"min_sales_count": 0}`,
+			wantErr: "min_sales_count: must be at least 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetForTesting()
+			defer ResetForTesting()
+
+			tempDir, cleanup := testSetup(t)
+			defer cleanup()
+
+			if tt.content != "" {
+				configPath := filepath.Join(tempDir, "config", "application.json")
+				if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+					t.Fatalf("Failed to write test config: %v", err)
+				}
+			}
+
+			cfg, err := LoadConfig()
+			if err == nil {
+				t.Fatal("LoadConfig() expected an error")
+			}
+			if cfg != nil {
+				t.Errorf("LoadConfig() returned a config alongside error: %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	data, err := json.Marshal(validTestConfig())
+	if err != nil {
+		t.Fatalf("Failed to marshal test config: %v", err)
+	}
+
+	cfg, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, validTestConfig()) {
+		t.Errorf("Parse() = %+v, want %+v", *cfg, *validTestConfig())
+	}
+
+	if _, err := Parse(strings.NewReader(`{"min_sales_count": 0}`)); err == nil {
+		t.Error("Parse() expected a validation error")
+	}
+}
+
+func TestLoad_ReturnsIndependentConfigs(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "tenant.json")
+
+	data, err := json.Marshal(validTestConfig())
+	if err != nil {
+		t.Fatalf("Failed to marshal test config: %v", err)
+	}
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	first, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	second, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if first == second {
+		t.Error("Load() returned the same instance twice")
+	}
+
+	first.MinSalesCount = 10
+	if second.MinSalesCount != 3 {
+		t.Errorf("Modifying one config changed the other, MinSalesCount = %v", second.MinSalesCount)
+	}
+}
@@ -0,0 +1,333 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+)
+
+// EnvPrefix is prepended to the upper-cased field path to build the name of
+// the environment variable overriding a field, e.g. VALUATION_MIN_SALES_COUNT
+const EnvPrefix = "VALUATION_"
+
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+	SourceProfile = "profile"
+)
+
+// Sources maps every field path to the layer its effective value came from
+type Sources map[string]string
+
+// field is a single configurable value addressed by its JSON path
+type field struct {
+	path   string
+	float  *float64
+	number *int
+	text   *string
+}
+
+func (f field) String() string {
+	if f.text != nil {
+		return *f.text
+	}
+	if f.number != nil {
+		return strconv.Itoa(*f.number)
+	}
+	return strconv.FormatFloat(*f.float, 'g', -1, 64)
+}
+
+func (f field) set(value string) error {
+	if f.text != nil {
+		*f.text = strings.TrimSpace(value)
+		return nil
+	}
+	if f.number != nil {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s: invalid integer %q", f.path, value)
+		}
+		*f.number = n
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return fmt.Errorf("%s: invalid number %q", f.path, value)
+	}
+	*f.float = v
+	return nil
+}
+
+func (f field) envName() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(f.path, ".", "_"))
+}
+
+func fields(c *Config) []field {
+	return []field{
+		{path: "criteria_weights.property_type", float: &c.CriteriaWeights.PropertyType},
+		{path: "criteria_weights.bedrooms", float: &c.CriteriaWeights.Bedrooms},
+		{path: "criteria_weights.bathrooms", float: &c.CriteriaWeights.Bathrooms},
+		{path: "criteria_weights.size", float: &c.CriteriaWeights.Size},
+		{path: "criteria_weights.recency", float: &c.CriteriaWeights.Recency},
+		{path: "criteria_weights.status", float: &c.CriteriaWeights.Status},
+		{path: "criteria_weights.neighborhood", float: &c.CriteriaWeights.Neighborhood},
+		{path: "time_scores.three_months", float: &c.TimeScores.ThreeMonths},
+		{path: "time_scores.six_months", float: &c.TimeScores.SixMonths},
+		{path: "time_scores.nine_months", float: &c.TimeScores.NineMonths},
+		{path: "status_scores.sold", float: &c.StatusScores.Sold},
+		{path: "status_scores.pending", float: &c.StatusScores.Pending},
+		{path: "status_scores.active", float: &c.StatusScores.Active},
+		{path: "min_sales_count", number: &c.MinSalesCount},
+		{path: "school_district.min_sales", number: &c.SchoolDistrict.MinSales},
+		{path: "valuation_type", text: &c.ValuationType},
+		{path: "rental.expense_ratio", float: &c.Rental.ExpenseRatio},
+		{path: "data_quality.invalid", text: &c.DataQuality.Invalid},
+		{path: "sale_to_list.mode", text: &c.SaleToList.Mode},
+		{path: "sale_to_list.min_sales", number: &c.SaleToList.MinSales},
+	}
+}
+
+// Defaults returns the built-in configuration used when no other layer sets a value
+func Defaults() *Config {
+	cfg := &Config{MinSalesCount: 3}
+	cfg.CriteriaWeights.PropertyType = 0.1
+	cfg.CriteriaWeights.Bedrooms = 0.05
+	cfg.CriteriaWeights.Bathrooms = 0.05
+	cfg.CriteriaWeights.Size = 0.2
+	cfg.CriteriaWeights.Recency = 0.5
+	cfg.CriteriaWeights.Status = 0.1
+	cfg.TimeScores.ThreeMonths = 1.0
+	cfg.TimeScores.SixMonths = 0.5
+	cfg.TimeScores.NineMonths = 0.25
+	cfg.StatusScores.Sold = 1.0
+	cfg.StatusScores.Pending = 0.6
+	cfg.StatusScores.Active = 0.4
+	cfg.ValuationType = ValuationSale
+	cfg.Rental.ExpenseRatio = 0.4
+	cfg.DataQuality.Invalid = DataQualityKeep
+	cfg.SaleToList.Mode = SaleToListOff
+	cfg.SaleToList.MinSales = 3
+	return cfg
+}
+
+// LoadLayered builds the configuration from the built-in defaults, then the
+// file at path (skipped when path is empty), then VALUATION_* environment
+// variables and finally the flag overrides keyed by field path. The result is
+// validated once every layer has been applied and the designated cities of
+// the address matching are set from it. The environment and flag layers also
+// apply on top of the settings of every profile.
+func LoadLayered(path string, flags map[string]string) (*Config, Sources, error) {
+	var data []byte
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, nil, fmt.Errorf("failed to read config file: %v", err)
+		}
+	}
+	return loadLayered(path, data, flags)
+}
+
+// loadLayered builds the configuration as LoadLayered from the content of
+// the file at path, already read
+func loadLayered(path string, data []byte, flags map[string]string) (*Config, Sources, error) {
+	cfg := Defaults()
+	sources := Sources{}
+	for _, f := range fields(cfg) {
+		sources[f.path] = SourceDefault
+	}
+
+	if path != "" {
+		if err := applyFile(cfg, sources, path, data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, f := range fields(cfg) {
+		if value, ok := os.LookupEnv(f.envName()); ok {
+			cfg.overrides = append(cfg.overrides, override{
+				path:   f.path,
+				value:  value,
+				name:   "environment variable " + f.envName(),
+				source: SourceEnv + ":" + f.envName(),
+			})
+		}
+	}
+	for _, f := range fields(cfg) {
+		if value, ok := flags[f.path]; ok {
+			cfg.overrides = append(cfg.overrides, override{
+				path:   f.path,
+				value:  value,
+				name:   "flag -" + f.path,
+				source: SourceFlag + ":-" + f.path,
+			})
+		}
+	}
+
+	rescaled, err := cfg.applyOverrides()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, o := range cfg.overrides {
+		sources[o.path] = o.source
+	}
+	for _, path := range rescaled {
+		sources[path] += ", rescaled"
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	address.SetDesignatedCities(cfg.Address.DesignatedCities)
+
+	return cfg, sources, nil
+}
+
+// override is a field value set by an environment variable or a flag
+type override struct {
+	path   string
+	value  string
+	name   string
+	source string
+}
+
+// applyOverrides sets the fields of the environment and flag overrides, in
+// the order they were loaded so that a flag wins over the environment, and
+// returns the paths of the criteria weights rescaled to keep their sum at 1
+func (c *Config) applyOverrides() ([]string, error) {
+	byPath := map[string]field{}
+	for _, f := range fields(c) {
+		byPath[f.path] = f
+	}
+
+	overridden := map[string]bool{}
+	for _, o := range c.overrides {
+		if err := byPath[o.path].set(o.value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", o.name, err)
+		}
+		overridden[o.path] = true
+	}
+	return c.rescaleWeights(overridden), nil
+}
+
+// rescaleWeights scales the criteria weights that were not overridden so
+// that the weights sum to 1 again, overriding one weight such as
+// VALUATION_CRITERIA_WEIGHTS_RECENCY=0.4 then takes its share from the
+// others in proportion. Nothing is rescaled when every weight or none was
+// overridden, or when the overridden weights alone exceed 1.
+func (c *Config) rescaleWeights(overridden map[string]bool) []string {
+	var fixed, free float64
+	var rescaled []field
+	weights := 0
+	for _, f := range fields(c) {
+		if !strings.HasPrefix(f.path, "criteria_weights.") {
+			continue
+		}
+		weights++
+		if overridden[f.path] {
+			fixed += *f.float
+		} else {
+			free += *f.float
+			rescaled = append(rescaled, f)
+		}
+	}
+	if len(rescaled) == weights || free <= 0 || fixed > 1 {
+		return nil
+	}
+
+	scale := (1 - fixed) / free
+	paths := make([]string, len(rescaled))
+	for i, f := range rescaled {
+		*f.float = math.Round(*f.float*scale*1e6) / 1e6
+		paths[i] = f.path
+	}
+	return paths
+}
+
+// applyFile decodes the file content on top of cfg and records which fields
+// it set
+func applyFile(cfg *Config, sources Sources, path string, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("failed to parse config file: %v", err)
+	}
+
+	for _, field := range presentFields(data) {
+		sources[field] = SourceFile + ":" + path
+	}
+
+	return nil
+}
+
+// presentFields returns the paths of the fields set in a JSON configuration
+func presentFields(data []byte) []string {
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(data, &present); err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, f := range fields(&Config{}) {
+		section, key, nested := strings.Cut(f.path, ".")
+		raw, ok := present[section]
+		if !ok {
+			continue
+		}
+		if nested {
+			var values map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &values); err != nil {
+				continue
+			}
+			if _, ok := values[key]; !ok {
+				continue
+			}
+		}
+		paths = append(paths, f.path)
+	}
+	return paths
+}
+
+// RegisterFlags defines a flag for every configuration field on the flag set,
+// named after the field path, e.g. -criteria_weights.recency
+func RegisterFlags(fs *flag.FlagSet) {
+	defaults := Defaults()
+	for _, f := range fields(defaults) {
+		fs.String(f.path, "", fmt.Sprintf("override %s (default %s)", f.path, f))
+	}
+}
+
+// FlagOverrides returns the configuration fields explicitly set on a parsed
+// flag set registered with RegisterFlags
+func FlagOverrides(fs *flag.FlagSet) map[string]string {
+	known := map[string]bool{}
+	for _, f := range fields(&Config{}) {
+		known[f.path] = true
+	}
+
+	overrides := map[string]string{}
+	fs.Visit(func(fl *flag.Flag) {
+		if known[fl.Name] {
+			overrides[fl.Name] = fl.Value.String()
+		}
+	})
+	return overrides
+}
+
+// Describe returns one "path = value (source)" line per field
+func Describe(cfg *Config, sources Sources) []string {
+	var lines []string
+	for _, f := range fields(cfg) {
+		lines = append(lines, fmt.Sprintf("%s = %s (%s)", f.path, f, sources[f.path]))
+	}
+	return lines
+}
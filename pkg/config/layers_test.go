@@ -0,0 +1,176 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+)
+
+func TestLoadLayered(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "application.json")
+	fileContent := `{"criteria_weights": {"size": 0.15, "recency": 0.55}, "min_sales_count": 4}`
+	if err := os.WriteFile(configPath, []byte(fileContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	t.Setenv("VALUATION_MIN_SALES_COUNT", "5")
+	t.Setenv("VALUATION_STATUS_SCORES_ACTIVE", "0.3")
+
+	cfg, sources, err := LoadLayered(configPath, map[string]string{
+		"status_scores.active": "0.2",
+	})
+	if err != nil {
+		t.Fatalf("LoadLayered() failed: %v", err)
+	}
+
+	tests := []struct {
+		path       string
+		got        float64
+		want       float64
+		wantSource string
+	}{
+		{"criteria_weights.property_type", cfg.CriteriaWeights.PropertyType, 0.1, SourceDefault},
+		{"criteria_weights.size", cfg.CriteriaWeights.Size, 0.15, SourceFile + ":" + configPath},
+		{"criteria_weights.recency", cfg.CriteriaWeights.Recency, 0.55, SourceFile + ":" + configPath},
+		{"min_sales_count", float64(cfg.MinSalesCount), 5, SourceEnv + ":VALUATION_MIN_SALES_COUNT"},
+		{"status_scores.active", cfg.StatusScores.Active, 0.2, SourceFlag + ":-status_scores.active"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.path, tt.got, tt.want)
+			}
+			if sources[tt.path] != tt.wantSource {
+				t.Errorf("%s source = %v, want %v", tt.path, sources[tt.path], tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestLoadLayered_SetsDesignatedCities(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "application.json")
+	fileContent := `{"address": {"designated_cities": ["Danbury"]}}`
+	if err := os.WriteFile(configPath, []byte(fileContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	t.Cleanup(func() { address.SetDesignatedCities(nil) })
+
+	if address.SameCity("Danbury", "Danbury Town") {
+		t.Fatal("SameCity() before loading = true, want false")
+	}
+	if _, _, err := LoadLayered(configPath, nil); err != nil {
+		t.Fatalf("LoadLayered() failed: %v", err)
+	}
+	if !address.SameCity("Danbury", "Danbury Town") {
+		t.Error("SameCity() after loading = false, want true")
+	}
+	if address.SameCity("Norwalk", "Norwalk Town") {
+		t.Error("SameCity() of a city not designated = true, want false")
+	}
+}
+
+func TestLoadLayered_RescalesWeights(t *testing.T) {
+	t.Setenv("VALUATION_CRITERIA_WEIGHTS_RECENCY", "0.4")
+
+	cfg, sources, err := LoadLayered("", nil)
+	if err != nil {
+		t.Fatalf("LoadLayered() failed: %v", err)
+	}
+
+	tests := []struct {
+		path       string
+		got        float64
+		want       float64
+		wantSource string
+	}{
+		{"criteria_weights.recency", cfg.CriteriaWeights.Recency, 0.4, SourceEnv + ":VALUATION_CRITERIA_WEIGHTS_RECENCY"},
+		{"criteria_weights.property_type", cfg.CriteriaWeights.PropertyType, 0.12, SourceDefault + ", rescaled"},
+		{"criteria_weights.bedrooms", cfg.CriteriaWeights.Bedrooms, 0.06, SourceDefault + ", rescaled"},
+		{"criteria_weights.size", cfg.CriteriaWeights.Size, 0.24, SourceDefault + ", rescaled"},
+		{"criteria_weights.neighborhood", cfg.CriteriaWeights.Neighborhood, 0, SourceDefault + ", rescaled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.path, tt.got, tt.want)
+			}
+			if sources[tt.path] != tt.wantSource {
+				t.Errorf("%s source = %v, want %v", tt.path, sources[tt.path], tt.wantSource)
+			}
+		})
+	}
+
+	// the same override applies to a profile that sets every weight
+	cfg.Profiles = map[string]Profile{"rental": {Settings: []byte(`{"criteria_weights": {"property_type": 0.1, "bedrooms": 0.15, "bathrooms": 0.1, "size": 0.15, "recency": 0.4, "status": 0.1}}`)}}
+	cfg.overrides[0].value = "0.6"
+	rental, err := cfg.Profile("rental")
+	if err != nil {
+		t.Fatalf("Profile() failed: %v", err)
+	}
+	if rental.CriteriaWeights.Recency != 0.6 || rental.CriteriaWeights.Bedrooms != 0.1 {
+		t.Errorf("rental weights = %+v, want recency 0.6 and the others rescaled", rental.CriteriaWeights)
+	}
+}
+
+func TestLoadLayered_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		flags map[string]string
+	}{
+		{
+			name: "invalid environment value",
+			env:  map[string]string{"VALUATION_MIN_SALES_COUNT": "many"},
+		},
+		{
+			name:  "invalid flag value",
+			flags: map[string]string{"criteria_weights.recency": "high"},
+		},
+		{
+			name:  "override breaks validation",
+			flags: map[string]string{"criteria_weights.recency": "1.5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			if _, _, err := LoadLayered("", tt.flags); err == nil {
+				t.Error("LoadLayered() expected an error")
+			}
+		})
+	}
+}
+
+func TestFlagOverrides(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	other := fs.String("data", "", "")
+	RegisterFlags(fs)
+
+	if err := fs.Parse([]string{"-data", "listings.json", "-min_sales_count", "6"}); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	overrides := FlagOverrides(fs)
+	if len(overrides) != 1 || overrides["min_sales_count"] != "6" {
+		t.Errorf("FlagOverrides() = %v, want only min_sales_count=6", overrides)
+	}
+	if *other != "listings.json" {
+		t.Errorf("unrelated flag = %v, want listings.json", *other)
+	}
+}
+
+func TestDefaults_AreValid(t *testing.T) {
+	if err := Defaults().Validate(); err != nil {
+		t.Errorf("Defaults() are invalid: %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+)
+
+// DefaultProfile is the name of the profile made of the top-level settings,
+// every other profile inherits from it
+const DefaultProfile = "default"
+
+// RentalProfile is the name of the profile rental valuations use when the
+// configuration defines it, instead of the one matching the subject's address
+const RentalProfile = "rental"
+
+// ProfileMatch selects the markets a profile applies to, every non-empty
+// field must match the subject's location (case-insensitive)
+type ProfileMatch struct {
+	State  string   `json:"state,omitempty"`
+	County string   `json:"county,omitempty"`
+	City   string   `json:"city,omitempty"`
+	Zips   []string `json:"zips,omitempty"`
+}
+
+// Profile overrides part of the configuration for a market. Settings holds a
+// partial configuration applied on top of the profile it extends (the default
+// profile when Extends is empty).
+type Profile struct {
+	Extends  string          `json:"extends,omitempty"`
+	Match    ProfileMatch    `json:"match"`
+	Settings json.RawMessage `json:"settings,omitempty"`
+}
+
+// Location is the address of a subject property used to select a profile
+type Location struct {
+	State  string
+	County string
+	City   string
+	Zip    string
+}
+
+// specificity ranks a match by its most specific criteria so that a zip
+// profile wins over a city one, a city over a county and a county over a state
+func (m ProfileMatch) specificity() int {
+	score := 0
+	if len(m.Zips) > 0 {
+		score += 8
+	}
+	if m.City != "" {
+		score += 4
+	}
+	if m.County != "" {
+		score += 2
+	}
+	if m.State != "" {
+		score++
+	}
+	return score
+}
+
+func (m ProfileMatch) matches(loc Location) bool {
+	if m.specificity() == 0 {
+		return false
+	}
+	if m.State != "" && !strings.EqualFold(m.State, strings.TrimSpace(loc.State)) {
+		return false
+	}
+	if m.County != "" && !strings.EqualFold(m.County, strings.TrimSpace(loc.County)) {
+		return false
+	}
+	if m.City != "" && !address.SameCity(m.City, loc.City) {
+		return false
+	}
+	if len(m.Zips) > 0 {
+		for _, zip := range m.Zips {
+			if strings.EqualFold(zip, strings.TrimSpace(loc.Zip)) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// SelectProfile returns the name of the most specific profile matching the
+// location, or DefaultProfile when none matches
+func (c *Config) SelectProfile(loc Location) string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	selected, best := DefaultProfile, 0
+	for _, name := range names {
+		match := c.Profiles[name].Match
+		if score := match.specificity(); score > best && match.matches(loc) {
+			selected, best = name, score
+		}
+	}
+	return selected
+}
+
+// Profile returns the configuration of the named profile with its inherited
+// settings and then the environment and flag overrides applied, the returned
+// configuration has no profiles of its own
+func (c *Config) Profile(name string) (*Config, error) {
+	resolved := *c
+	resolved.Profiles = nil
+
+	if name == DefaultProfile || name == "" {
+		return &resolved, nil
+	}
+
+	var chain []Profile
+	seen := map[string]bool{}
+	for current := name; current != "" && current != DefaultProfile; {
+		if seen[current] {
+			return nil, fmt.Errorf("profile %q has an inheritance cycle", name)
+		}
+		seen[current] = true
+
+		profile, ok := c.Profiles[current]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q", current)
+		}
+		chain = append(chain, profile)
+		current = profile.Extends
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		if len(chain[i].Settings) == 0 {
+			continue
+		}
+		if err := applySettings(&resolved, chain[i].Settings); err != nil {
+			return nil, fmt.Errorf("invalid settings of profile %q: %v", name, err)
+		}
+	}
+
+	// the environment and flags win over the profile settings as they win
+	// over the file
+	if _, err := resolved.applyOverrides(); err != nil {
+		return nil, err
+	}
+
+	return &resolved, nil
+}
+
+// Override returns a validated copy of the configuration with the partial
+// JSON settings applied on top of it
+func (c *Config) Override(settings json.RawMessage) (*Config, error) {
+	overridden := *c
+	overridden.Profiles = nil
+
+	if len(settings) > 0 {
+		if err := applySettings(&overridden, settings); err != nil {
+			return nil, err
+		}
+	}
+	if err := overridden.Validate(); err != nil {
+		return nil, err
+	}
+
+	overridden.Profiles = c.Profiles
+	return &overridden, nil
+}
+
+// applySettings decodes a partial configuration on top of cfg, settings
+// cannot define profiles or change the address matching
+func applySettings(cfg *Config, settings json.RawMessage) error {
+	// the curves and cities are copied so that decoding into them does not
+	// overwrite the arrays shared with the configuration cfg was copied from
+	cfg.MarketSignals.DaysOnMarket = append([]CurvePoint(nil), cfg.MarketSignals.DaysOnMarket...)
+	cfg.MarketSignals.PriceCut = append([]CurvePoint(nil), cfg.MarketSignals.PriceCut...)
+	cfg.MarketSignals.PriceCutAge = append([]CurvePoint(nil), cfg.MarketSignals.PriceCutAge...)
+	designated := cfg.Address.DesignatedCities
+	cfg.Address.DesignatedCities = append([]string(nil), designated...)
+
+	decoder := json.NewDecoder(bytes.NewReader(settings))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("failed to parse settings: %v", err)
+	}
+	if cfg.Profiles != nil {
+		return fmt.Errorf("settings must not define profiles")
+	}
+	if !slices.Equal(cfg.Address.DesignatedCities, designated) {
+		return fmt.Errorf("settings must not change address.designated_cities")
+	}
+	return nil
+}
+
+// ProfileSources returns the sources of the named profile's values, fields set
+// by the profile or one it extends are attributed to that profile unless an
+// environment variable or a flag overrides them
+func (c *Config) ProfileSources(name string, base Sources) Sources {
+	sources := Sources{}
+	for path, source := range base {
+		sources[path] = source
+	}
+
+	var chain []string
+	seen := map[string]bool{}
+	for current := name; current != "" && current != DefaultProfile && !seen[current]; current = c.Profiles[current].Extends {
+		seen[current] = true
+		chain = append(chain, current)
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, path := range presentFields(c.Profiles[chain[i]].Settings) {
+			sources[path] = SourceProfile + ":" + chain[i]
+		}
+	}
+	for _, o := range c.overrides {
+		sources[o.path] = o.source
+	}
+	return sources
+}
+
+// validateProfiles resolves every profile and validates the result
+func (c *Config) validateProfiles() ValidationError {
+	var errs ValidationError
+
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := "profiles." + name
+		if name == DefaultProfile {
+			errs = append(errs, FieldError{path, "name is reserved for the top-level settings"})
+			continue
+		}
+
+		resolved, err := c.Profile(name)
+		if err != nil {
+			errs = append(errs, FieldError{path, err.Error()})
+			continue
+		}
+
+		if err := resolved.Validate(); err != nil {
+			for _, fe := range err.(ValidationError) {
+				errs = append(errs, FieldError{path + ".settings." + fe.Field, fe.Problem})
+			}
+		}
+	}
+
+	return errs
+}
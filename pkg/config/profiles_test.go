@@ -0,0 +1,273 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func profilesTestConfig() *Config {
+	cfg := validTestConfig()
+	cfg.Profiles = map[string]Profile{
+		"connecticut": {
+			Match:    ProfileMatch{State: "CT"},
+			Settings: json.RawMessage(`{"min_sales_count": 2}`),
+		},
+		"danbury": {
+			Extends:  "connecticut",
+			Match:    ProfileMatch{State: "CT", City: "Danbury"},
+			Settings: json.RawMessage(`{"time_scores": {"nine_months": 0.4}}`),
+		},
+		"downtown": {
+			Match:    ProfileMatch{Zips: []string{"06810"}},
+			Settings: json.RawMessage(`{"criteria_weights": {"size": 0.3, "recency": 0.4}}`),
+		},
+	}
+	return cfg
+}
+
+func TestConfig_SelectProfile(t *testing.T) {
+	cfg := profilesTestConfig()
+
+	tests := []struct {
+		name     string
+		location Location
+		want     string
+	}{
+		{
+			name:     "no matching profile",
+			location: Location{State: "NY", City: "New York"},
+			want:     DefaultProfile,
+		},
+		{
+			name:     "state match",
+			location: Location{State: "CT", City: "Norwalk"},
+			want:     "connecticut",
+		},
+		{
+			name:     "city match is case-insensitive",
+			location: Location{State: "ct", City: "DANBURY"},
+			want:     "danbury",
+		},
+		{
+			name:     "zip match wins over city",
+			location: Location{State: "CT", City: "Danbury", Zip: "06810"},
+			want:     "downtown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.SelectProfile(tt.location); got != tt.want {
+				t.Errorf("SelectProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Profile(t *testing.T) {
+	cfg := profilesTestConfig()
+
+	danbury, err := cfg.Profile("danbury")
+	if err != nil {
+		t.Fatalf("Profile() failed: %v", err)
+	}
+
+	if danbury.TimeScores.NineMonths != 0.4 {
+		t.Errorf("NineMonths = %v, want 0.4 from the profile", danbury.TimeScores.NineMonths)
+	}
+	if danbury.MinSalesCount != 2 {
+		t.Errorf("MinSalesCount = %v, want 2 inherited from connecticut", danbury.MinSalesCount)
+	}
+	if danbury.TimeScores.SixMonths != 0.5 {
+		t.Errorf("SixMonths = %v, want 0.5 inherited from the default profile", danbury.TimeScores.SixMonths)
+	}
+	if danbury.Profiles != nil {
+		t.Error("resolved profile should not have profiles")
+	}
+	if cfg.TimeScores.NineMonths != 0.25 || cfg.MinSalesCount != 3 {
+		t.Error("Profile() modified the base configuration")
+	}
+
+	sources := cfg.ProfileSources("danbury", Sources{"min_sales_count": SourceDefault, "time_scores.six_months": SourceDefault})
+	if sources["min_sales_count"] != SourceProfile+":connecticut" {
+		t.Errorf("min_sales_count source = %v, want profile:connecticut", sources["min_sales_count"])
+	}
+	if sources["time_scores.six_months"] != SourceDefault {
+		t.Errorf("time_scores.six_months source = %v, want default", sources["time_scores.six_months"])
+	}
+
+	if _, err := cfg.Profile("manhattan"); err == nil {
+		t.Error("Profile() expected an error for an unknown profile")
+	}
+}
+
+func TestConfig_ProfileCurves(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.MarketSignals.DaysOnMarket = []CurvePoint{{At: 30, Factor: 1}, {At: 180, Factor: 0.5}}
+	cfg.Profiles = map[string]Profile{
+		"hot": {
+			Match:    ProfileMatch{City: "Stamford"},
+			Settings: json.RawMessage(`{"market_signals": {"days_on_market": [{"at": 14, "factor": 1}, {"at": 60, "factor": 0.4}]}}`),
+		},
+	}
+
+	hot, err := cfg.Profile("hot")
+	if err != nil {
+		t.Fatalf("Profile() failed: %v", err)
+	}
+
+	if got := hot.MarketSignals.DaysOnMarket; len(got) != 2 || got[1] != (CurvePoint{At: 60, Factor: 0.4}) {
+		t.Errorf("DaysOnMarket = %v, want the profile curve", got)
+	}
+	if got := cfg.MarketSignals.DaysOnMarket; got[0] != (CurvePoint{At: 30, Factor: 1}) || got[1] != (CurvePoint{At: 180, Factor: 0.5}) {
+		t.Errorf("Profile() modified the base curve: %v", got)
+	}
+}
+
+func TestConfig_ValidateProfiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles map[string]Profile
+		wantErr  string
+	}{
+		{
+			name: "invalid inherited settings",
+			profiles: map[string]Profile{
+				"bad": {Settings: json.RawMessage(`{"min_sales_count": 0}`)},
+			},
+			wantErr: "profiles.bad.settings.min_sales_count",
+		},
+		{
+			name: "inheritance cycle",
+			profiles: map[string]Profile{
+				"a": {Extends: "b"},
+				"b": {Extends: "a"},
+			},
+			wantErr: "inheritance cycle",
+		},
+		{
+			name: "unknown parent",
+			profiles: map[string]Profile{
+				"a": {Extends: "missing"},
+			},
+			wantErr: `unknown profile "missing"`,
+		},
+		{
+			name: "unknown settings key",
+			profiles: map[string]Profile{
+				"a": {Settings: json.RawMessage(`{"max_sales_count": 3}`)},
+			},
+			wantErr: "unknown field",
+		},
+		{
+			name: "reserved name",
+			profiles: map[string]Profile{
+				DefaultProfile: {},
+			},
+			wantErr: "reserved",
+		},
+		{
+			name: "designated cities",
+			profiles: map[string]Profile{
+				"a": {Settings: json.RawMessage(`{"address": {"designated_cities": ["Danbury"]}}`)},
+			},
+			wantErr: "address.designated_cities",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			cfg.Profiles = tt.profiles
+
+			err := cfg.Validate()
+			if err == nil {
+				t.Fatal("Validate() expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := profilesTestConfig().Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestConfig_Override(t *testing.T) {
+	cfg := profilesTestConfig()
+
+	overridden, err := cfg.Override(json.RawMessage(`{"status_scores": {"active": 0.2}}`))
+	if err != nil {
+		t.Fatalf("Override() failed: %v", err)
+	}
+	if overridden.StatusScores.Active != 0.2 {
+		t.Errorf("Active = %v, want 0.2", overridden.StatusScores.Active)
+	}
+	if overridden.StatusScores.Pending != 0.6 {
+		t.Errorf("Pending = %v, want 0.6 from the base configuration", overridden.StatusScores.Pending)
+	}
+	if cfg.StatusScores.Active != 0.4 {
+		t.Error("Override() modified the base configuration")
+	}
+	if len(overridden.Profiles) != len(cfg.Profiles) {
+		t.Error("Override() dropped the profiles")
+	}
+
+	if _, err := cfg.Override(json.RawMessage(`{"min_sales_count": 0}`)); err == nil {
+		t.Error("Override() expected a validation error")
+	}
+	if _, err := cfg.Override(json.RawMessage(`{"profiles": {}}`)); err == nil {
+		t.Error("Override() expected an error for settings defining profiles")
+	}
+	if _, err := cfg.Override(json.RawMessage(`{"address": {"designated_cities": ["Danbury"]}}`)); err == nil {
+		t.Error("Override() expected an error for settings changing the designated cities")
+	}
+}
+
+func TestLoadLayered_OverridesProfiles(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "application.json")
+	fileContent := `{"profiles": {"ct": {"match": {"state": "CT"}, "settings": {"min_sales_count": 2, "time_scores": {"six_months": 0.4}, "status_scores": {"active": 0.3}}}}}`
+	if err := os.WriteFile(configPath, []byte(fileContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	t.Setenv("VALUATION_MIN_SALES_COUNT", "7")
+	t.Setenv("VALUATION_STATUS_SCORES_ACTIVE", "0.2")
+
+	cfg, sources, err := LoadLayered(configPath, map[string]string{"status_scores.active": "0.1"})
+	if err != nil {
+		t.Fatalf("LoadLayered() failed: %v", err)
+	}
+	ct, err := cfg.Profile("ct")
+	if err != nil {
+		t.Fatalf("Profile() failed: %v", err)
+	}
+	sources = cfg.ProfileSources("ct", sources)
+
+	tests := []struct {
+		path       string
+		got        float64
+		want       float64
+		wantSource string
+	}{
+		{"min_sales_count", float64(ct.MinSalesCount), 7, SourceEnv + ":VALUATION_MIN_SALES_COUNT"},
+		{"status_scores.active", ct.StatusScores.Active, 0.1, SourceFlag + ":-status_scores.active"},
+		{"time_scores.six_months", ct.TimeScores.SixMonths, 0.4, SourceProfile + ":ct"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.path, tt.got, tt.want)
+			}
+			if sources[tt.path] != tt.wantSource {
+				t.Errorf("%s source = %v, want %v", tt.path, sources[tt.path], tt.wantSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot is an immutable version of a reloadable configuration, callers
+// keep using the snapshot they got even if the file is reloaded meanwhile
+type Snapshot struct {
+	Config   *Config
+	Version  uint64
+	Hash     string
+	LoadedAt time.Time
+}
+
+// Reloader holds a configuration loaded from a file and swaps it atomically
+// when the file changes. A changed file is validated before it replaces the
+// current snapshot, invalid changes and read errors are reported to OnError
+// once and ignored.
+type Reloader struct {
+	// OnReload is called after a new snapshot has been swapped in
+	OnReload func(*Snapshot)
+	// OnError is called when the file cannot be read or is invalid
+	OnError func(error)
+
+	path    string
+	flags   map[string]string
+	current atomic.Pointer[Snapshot]
+	mu      sync.Mutex
+
+	// failedHash and failedErr are the content and error of the last invalid
+	// file, so that it is not reported again until it changes
+	failedHash string
+	failedErr  error
+	// readErr is the message of the last error reading the file, empty once
+	// it is read again
+	readErr string
+}
+
+// NewReloader loads the configuration file at path with the environment and
+// flag layers as LoadLayered does, every reload applies the same layers. It
+// fails if the initial configuration is invalid.
+func NewReloader(path string, flags map[string]string) (*Reloader, error) {
+	r := &Reloader{path: path, flags: flags}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	cfg, _, err := loadLayered(path, data, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	r.current.Store(&Snapshot{
+		Config:   cfg,
+		Version:  1,
+		Hash:     hash(data),
+		LoadedAt: time.Now(),
+	})
+
+	return r, nil
+}
+
+// Snapshot returns the current configuration snapshot
+func (r *Reloader) Snapshot() *Snapshot {
+	return r.current.Load()
+}
+
+// Config returns the configuration of the current snapshot
+func (r *Reloader) Config() *Config {
+	return r.Snapshot().Config
+}
+
+// Reload reads the file and swaps in a new snapshot if its content changed
+// and is valid, it reports whether a new snapshot was stored. The error of
+// an invalid content is returned until the file changes again but only
+// reported to OnError the first time, as is a read error until the file is
+// read again or fails differently.
+func (r *Reloader) Reload() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		err = fmt.Errorf("failed to read config file: %v", err)
+		if err.Error() == r.readErr {
+			return false, err
+		}
+		r.readErr = err.Error()
+		return false, r.fail(err)
+	}
+	r.readErr = ""
+
+	current := r.current.Load()
+	sum := hash(data)
+	if sum == current.Hash {
+		return false, nil
+	}
+	if sum == r.failedHash {
+		return false, r.failedErr
+	}
+
+	cfg, _, err := loadLayered(r.path, data, r.flags)
+	if err != nil {
+		r.failedHash, r.failedErr = sum, err
+		return false, r.fail(err)
+	}
+
+	snapshot := &Snapshot{
+		Config:   cfg,
+		Version:  current.Version + 1,
+		Hash:     sum,
+		LoadedAt: time.Now(),
+	}
+	r.current.Store(snapshot)
+
+	if r.OnReload != nil {
+		r.OnReload(snapshot)
+	}
+
+	return true, nil
+}
+
+// Watch polls the file every interval and reloads it when it changes until
+// the context is cancelled, it is meant to run in its own goroutine
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// errors are reported to OnError
+			r.Reload()
+		}
+	}
+}
+
+func (r *Reloader) fail(err error) error {
+	if r.OnError != nil {
+		r.OnError(err)
+	}
+	return err
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
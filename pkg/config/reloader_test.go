@@ -0,0 +1,232 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeTestConfig(t *testing.T, path string, cfg *Config) {
+	t.Helper()
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Failed to marshal test config: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+}
+
+func TestReloader_Reload(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "application.json")
+	writeTestConfig(t, configPath, validTestConfig())
+
+	reloader, err := NewReloader(configPath, nil)
+	if err != nil {
+		t.Fatalf("NewReloader() failed: %v", err)
+	}
+
+	var reloadErrors []error
+	reloader.OnError = func(err error) {
+		reloadErrors = append(reloadErrors, err)
+	}
+
+	first := reloader.Snapshot()
+	if first.Version != 1 || first.Hash == "" {
+		t.Fatalf("initial snapshot = %+v, want version 1 with a hash", first)
+	}
+
+	changed, err := reloader.Reload()
+	if err != nil || changed {
+		t.Errorf("Reload() of an unchanged file = %v, %v, want false, nil", changed, err)
+	}
+
+	updated := validTestConfig()
+	updated.MinSalesCount = 5
+	writeTestConfig(t, configPath, updated)
+
+	changed, err = reloader.Reload()
+	if err != nil || !changed {
+		t.Fatalf("Reload() of a changed file = %v, %v, want true, nil", changed, err)
+	}
+
+	second := reloader.Snapshot()
+	if second.Version != 2 || second.Hash == first.Hash {
+		t.Errorf("reloaded snapshot = %+v, want version 2 with a new hash", second)
+	}
+	if second.Config.MinSalesCount != 5 {
+		t.Errorf("MinSalesCount = %v, want 5", second.Config.MinSalesCount)
+	}
+	if first.Config.MinSalesCount != 3 {
+		t.Errorf("previous snapshot changed, MinSalesCount = %v, want 3", first.Config.MinSalesCount)
+	}
+
+	invalid := validTestConfig()
+	invalid.MinSalesCount = 0
+	writeTestConfig(t, configPath, invalid)
+
+	if _, err := reloader.Reload(); err == nil {
+		t.Error("Reload() of an invalid file expected an error")
+	}
+	if len(reloadErrors) != 1 {
+		t.Errorf("OnError called %d times, want 1", len(reloadErrors))
+	}
+	if reloader.Snapshot() != second {
+		t.Error("invalid file replaced the current snapshot")
+	}
+}
+
+func TestReloader_ReloadMissingFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "application.json")
+	writeTestConfig(t, configPath, validTestConfig())
+
+	reloader, err := NewReloader(configPath, nil)
+	if err != nil {
+		t.Fatalf("NewReloader() failed: %v", err)
+	}
+
+	var reloadErrors []error
+	reloader.OnError = func(err error) {
+		reloadErrors = append(reloadErrors, err)
+	}
+
+	if err := os.Remove(configPath); err != nil {
+		t.Fatalf("failed to remove the config file: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := reloader.Reload(); err == nil {
+			t.Error("Reload() of a missing file expected an error")
+		}
+	}
+	if len(reloadErrors) != 1 {
+		t.Errorf("OnError called %d times for a missing file, want 1", len(reloadErrors))
+	}
+
+	// the error is reported again after the file was read back
+	writeTestConfig(t, configPath, validTestConfig())
+	if _, err := reloader.Reload(); err != nil {
+		t.Fatalf("Reload() of the restored file error = %v", err)
+	}
+	os.Remove(configPath)
+	reloader.Reload()
+	if len(reloadErrors) != 2 {
+		t.Errorf("OnError called %d times after the file went missing again, want 2", len(reloadErrors))
+	}
+}
+
+func TestReloader_Watch(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "application.json")
+	writeTestConfig(t, configPath, validTestConfig())
+
+	reloader, err := NewReloader(configPath, nil)
+	if err != nil {
+		t.Fatalf("NewReloader() failed: %v", err)
+	}
+
+	reloaded := make(chan *Snapshot, 1)
+	reloader.OnReload = func(s *Snapshot) {
+		reloaded <- s
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		reloader.Watch(ctx, 10*time.Millisecond)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	// readers keep loading snapshots while the file is swapped
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if reloader.Config() == nil {
+					t.Error("Config() returned nil")
+					return
+				}
+			}
+		}()
+	}
+
+	updated := validTestConfig()
+	updated.StatusScores.Active = 0.3
+	writeTestConfig(t, configPath, updated)
+
+	select {
+	case s := <-reloaded:
+		if s.Config.StatusScores.Active != 0.3 {
+			t.Errorf("Active = %v, want 0.3", s.Config.StatusScores.Active)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Watch() did not reload the changed file")
+	}
+}
+
+func TestNewReloader_InvalidFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "application.json")
+	if err := os.WriteFile(configPath, []byte(`{"min_sales_count": 0}`), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	if _, err := NewReloader(configPath, nil); err == nil {
+		t.Error("NewReloader() expected an error")
+	}
+	if _, err := NewReloader(filepath.Join(t.TempDir(), "missing.json"), nil); err == nil {
+		t.Error("NewReloader() expected an error for a missing file")
+	}
+}
+
+func TestReloader_ReloadKeepsLayers(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "application.json")
+	if err := os.WriteFile(configPath, []byte(`{"min_sales_count": 4}`), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	t.Setenv("VALUATION_STATUS_SCORES_ACTIVE", "0.3")
+
+	reloader, err := NewReloader(configPath, map[string]string{"time_scores.six_months": "0.4"})
+	if err != nil {
+		t.Fatalf("NewReloader() failed: %v", err)
+	}
+
+	var reloadErrors []error
+	reloader.OnError = func(err error) {
+		reloadErrors = append(reloadErrors, err)
+	}
+
+	if err := os.WriteFile(configPath, []byte(`{"min_sales_count": 5}`), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	if changed, err := reloader.Reload(); err != nil || !changed {
+		t.Fatalf("Reload() of a changed file = %v, %v, want true, nil", changed, err)
+	}
+
+	cfg := reloader.Config()
+	if cfg.MinSalesCount != 5 || cfg.StatusScores.Active != 0.3 || cfg.TimeScores.SixMonths != 0.4 {
+		t.Errorf("reloaded config = min_sales_count %v, active %v, six_months %v, want 5 from the file, 0.3 from the environment and 0.4 from the flag",
+			cfg.MinSalesCount, cfg.StatusScores.Active, cfg.TimeScores.SixMonths)
+	}
+
+	// an invalid file is reported once however many times it is polled
+	if err := os.WriteFile(configPath, []byte(`{"min_sales_count": 0}`), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := reloader.Reload(); err == nil {
+			t.Error("Reload() of an invalid file expected an error")
+		}
+	}
+	if len(reloadErrors) != 1 {
+		t.Errorf("OnError called %d times, want 1", len(reloadErrors))
+	}
+}
@@ -0,0 +1,119 @@
+package criteria
+
+import (
+	"math"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+// CurvePoint is a point of a Curve
+type CurvePoint struct {
+	At     float64
+	Factor float64
+}
+
+// Curve is a piecewise linear function of points in increasing order, it
+// is flat before the first point and after the last one
+type Curve []CurvePoint
+
+// At returns the factor of the curve at x, 1 for a curve without points
+func (c Curve) At(x float64) float64 {
+	if len(c) == 0 {
+		return 1
+	}
+	if x <= c[0].At {
+		return c[0].Factor
+	}
+	for i := 1; i < len(c); i++ {
+		if x <= c[i].At {
+			a, b := c[i-1], c[i]
+			return a.Factor + (b.Factor-a.Factor)*(x-a.At)/(b.At-a.At)
+		}
+	}
+	return c[len(c)-1].Factor
+}
+
+// SignalCurves are the curves of the market signals. PriceCutAge gives the
+// share of a price cut's effect kept by the days since the price changed.
+type SignalCurves struct {
+	DaysOnMarket Curve
+	PriceCut     Curve
+	PriceCutAge  Curve
+}
+
+// MarketSignals scales the weight of a comparable that has not sold by how
+// long it has been on the market and how much its price was cut, both
+// signal a list price above the market. Unlike the other criteria it
+// returns a factor multiplying the weight instead of a weighted score.
+type MarketSignals struct {
+	Property models.Property
+	Curves   SignalCurves
+	// AsOf is the date the time on market is measured at, now when zero
+	AsOf time.Time
+}
+
+func NewMarketSignals(property models.Property, curves SignalCurves) *MarketSignals {
+	return &MarketSignals{
+		Property: property,
+		Curves:   curves,
+	}
+}
+
+func (m *MarketSignals) Evaluate() (float64, error) {
+	if m.Property.Status == "Closed" || m.Property.SalePrice > 0 {
+		return 1, nil
+	}
+
+	return m.Curves.DaysOnMarket.At(m.daysOnMarket()) * m.priceCutFactor(), nil
+}
+
+func (m *MarketSignals) asOf() int64 {
+	if m.AsOf.IsZero() {
+		return time.Now().Unix()
+	}
+	return m.AsOf.Unix()
+}
+
+// daysSince returns the days from a timestamp to the as-of date
+func (m *MarketSignals) daysSince(timestamp int64) float64 {
+	return math.Max(0, float64(m.asOf()-timestamp)/(24*60*60))
+}
+
+// daysOnMarket returns the days on market of the feed, or the days since
+// the listing date when the feed has none. With an as-of date the days are
+// counted from the listing date, the feed's count runs up to its export.
+func (m *MarketSignals) daysOnMarket() float64 {
+	if m.Property.ListingDate > 0 && (!m.AsOf.IsZero() || m.Property.DaysOnMarket <= 0) {
+		return m.daysSince(m.Property.ListingDate)
+	}
+	if m.Property.DaysOnMarket > 0 {
+		return float64(m.Property.DaysOnMarket)
+	}
+	return 0
+}
+
+// priceCutFactor returns the factor of the price cut, its effect fades with
+// the days since the price change when the feed has its date. A price
+// changed after the as-of date was not cut yet.
+func (m *MarketSignals) priceCutFactor() float64 {
+	factor := m.Curves.PriceCut.At(m.priceCutPercent())
+	if factor == 1 || m.Property.PriceChangeTimestamp <= 0 {
+		return factor
+	}
+	if m.Property.PriceChangeTimestamp > m.asOf() {
+		return 1
+	}
+	kept := m.Curves.PriceCutAge.At(m.daysSince(m.Property.PriceChangeTimestamp))
+	return 1 - (1-factor)*kept
+}
+
+// priceCutPercent returns how much lower the list price is than the
+// previous one in percent, 0 when it was not reduced
+func (m *MarketSignals) priceCutPercent() float64 {
+	previous := m.Property.PreviousListPrice
+	if previous <= m.Property.ListPrice || m.Property.ListPrice <= 0 {
+		return 0
+	}
+	return (previous - m.Property.ListPrice) / previous * 100
+}
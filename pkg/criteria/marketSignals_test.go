@@ -0,0 +1,141 @@
+package criteria
+
+import (
+	"testing"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+func TestCurveAt(t *testing.T) {
+	curve := Curve{{At: 30, Factor: 1}, {At: 90, Factor: 0.7}, {At: 180, Factor: 0.4}}
+
+	tests := []struct {
+		name  string
+		curve Curve
+		x     float64
+		want  float64
+	}{
+		{name: "empty curve", curve: nil, x: 100, want: 1},
+		{name: "before the first point", curve: curve, x: 0, want: 1},
+		{name: "on a point", curve: curve, x: 90, want: 0.7},
+		{name: "between points", curve: curve, x: 60, want: 0.85},
+		{name: "after the last point", curve: curve, x: 400, want: 0.4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.curve.At(tt.x); !almostEqual(got, tt.want, 1e-9) {
+				t.Errorf("At(%v) = %v, want %v", tt.x, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarketSignalsEvaluate(t *testing.T) {
+	asOf := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	now := asOf.Unix()
+	day := int64(24 * 60 * 60)
+	curves := SignalCurves{
+		DaysOnMarket: Curve{{At: 30, Factor: 1}, {At: 120, Factor: 0.7}},
+		PriceCut:     Curve{{At: 0, Factor: 1}, {At: 10, Factor: 0.5}},
+		PriceCutAge:  Curve{{At: 30, Factor: 1}, {At: 90, Factor: 0}},
+	}
+
+	tests := []struct {
+		name     string
+		property models.Property
+		want     float64
+	}{
+		{
+			name:     "fresh listing",
+			property: models.Property{Status: "Active", ListPrice: 500000, DaysOnMarket: 10},
+			want:     1,
+		},
+		{
+			name:     "stale listing",
+			property: models.Property{Status: "Active", ListPrice: 500000, DaysOnMarket: 150},
+			want:     0.7,
+		},
+		{
+			name:     "days on market from the listing date",
+			property: models.Property{Status: "Active", ListPrice: 500000, ListingDate: now - 75*day},
+			want:     0.85,
+		},
+		{
+			name:     "days on market of the feed counted up to the as-of date",
+			property: models.Property{Status: "Active", ListPrice: 500000, DaysOnMarket: 400, ListingDate: now - 75*day},
+			want:     0.85,
+		},
+		{
+			name:     "price cut after the as-of date",
+			property: models.Property{Status: "Active", ListPrice: 475000, PreviousListPrice: 500000, DaysOnMarket: 10, PriceChangeTimestamp: now + 5*day},
+			want:     1,
+		},
+		{
+			name:     "listed after the as-of date",
+			property: models.Property{Status: "Active", ListPrice: 500000, ListingDate: now + 200*day},
+			want:     1,
+		},
+		{
+			name:     "price cut",
+			property: models.Property{Status: "Under Contract", ListPrice: 475000, PreviousListPrice: 500000, DaysOnMarket: 10},
+			want:     0.75,
+		},
+		{
+			name:     "recent price cut",
+			property: models.Property{Status: "Active", ListPrice: 475000, PreviousListPrice: 500000, DaysOnMarket: 10, PriceChangeTimestamp: now - 20*day},
+			want:     0.75,
+		},
+		{
+			name:     "fading price cut",
+			property: models.Property{Status: "Active", ListPrice: 475000, PreviousListPrice: 500000, DaysOnMarket: 10, PriceChangeTimestamp: now - 60*day},
+			want:     0.875,
+		},
+		{
+			name:     "old price cut",
+			property: models.Property{Status: "Active", ListPrice: 475000, PreviousListPrice: 500000, DaysOnMarket: 100, PriceChangeTimestamp: now - 100*day},
+			want:     1 - 0.3*70/90,
+		},
+		{
+			name:     "stale listing with a price cut",
+			property: models.Property{Status: "Active", ListPrice: 450000, PreviousListPrice: 500000, DaysOnMarket: 120},
+			want:     0.35,
+		},
+		{
+			name:     "price increase",
+			property: models.Property{Status: "Active", ListPrice: 520000, PreviousListPrice: 500000, DaysOnMarket: 10},
+			want:     1,
+		},
+		{
+			name:     "closed sale",
+			property: models.Property{Status: "Closed", ListPrice: 450000, PreviousListPrice: 500000, SalePrice: 440000, DaysOnMarket: 200},
+			want:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signals := NewMarketSignals(tt.property, curves)
+			signals.AsOf = asOf
+			got, err := signals.Evaluate()
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if !almostEqual(got, tt.want, 1e-6) {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarketSignalsEvaluate_WithoutAsOf(t *testing.T) {
+	curves := SignalCurves{DaysOnMarket: Curve{{At: 30, Factor: 1}, {At: 120, Factor: 0.7}}}
+	listed := time.Now().Add(-75 * 24 * time.Hour).Unix()
+
+	// the feed's days on market are current without an as-of date
+	got, _ := NewMarketSignals(models.Property{Status: "Active", ListPrice: 500000, DaysOnMarket: 150, ListingDate: listed}, curves).Evaluate()
+	if !almostEqual(got, 0.7, 1e-6) {
+		t.Errorf("Evaluate() = %v, want 0.7", got)
+	}
+}
@@ -0,0 +1,43 @@
+package criteria
+
+import "github.com/krlosmederos/locqube-challenge/pkg/models"
+
+// Neighborhood scores a comparable by the smallest area it shares with the
+// subject: the same subdivision, then the same elementary, middle or high
+// school attendance zone. Unknown subdivisions and schools never match.
+type Neighborhood struct {
+	Property models.Property
+	Subject  models.Property
+	Weight   float64
+}
+
+func NewNeighborhood(property, subject models.Property, weight float64) *Neighborhood {
+	return &Neighborhood{
+		Property: property,
+		Subject:  subject,
+		Weight:   weight,
+	}
+}
+
+func (n *Neighborhood) Evaluate() (float64, error) {
+	if n.Weight == 0 {
+		return 0, nil
+	}
+
+	subdivision := n.Subject.SubdivisionName()
+	if subdivision != "" && n.Property.SubdivisionName() == subdivision {
+		return n.Weight, nil
+	}
+
+	subject, property := n.Subject.SchoolZones(), n.Property.SchoolZones()
+	score := 0.0
+	if subject.Elementary != "" && property.Elementary == subject.Elementary {
+		score = 0.75
+	} else if subject.Middle != "" && property.Middle == subject.Middle {
+		score = 0.5
+	} else if subject.High != "" && property.High == subject.High {
+		score = 0.25
+	}
+
+	return score * n.Weight, nil
+}
@@ -0,0 +1,82 @@
+package criteria
+
+import (
+	"testing"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+func TestNeighborhoodEvaluate(t *testing.T) {
+	subject := models.Property{
+		Subdivision: "Regency at Rivington",
+		Schools:     models.Schools{Elementary: "King Street", Middle: "Rogers Park", High: "Danbury"},
+	}
+
+	tests := []struct {
+		name          string
+		subdivision   string
+		schools       models.Schools
+		weight        float64
+		expectedScore float64
+	}{
+		{
+			name:          "same subdivision",
+			subdivision:   "REGENCY AT RIVINGTON",
+			weight:        0.2,
+			expectedScore: 0.2,
+		},
+		{
+			name:          "same elementary school",
+			schools:       models.Schools{Elementary: "King Street", Middle: "Broadview", High: "Danbury"},
+			weight:        0.2,
+			expectedScore: 0.15,
+		},
+		{
+			name:          "same middle school",
+			schools:       models.Schools{Elementary: "Ellsworth", Middle: "Rogers Park", High: "Danbury"},
+			weight:        0.2,
+			expectedScore: 0.1,
+		},
+		{
+			name:          "same high school",
+			subdivision:   "Candlewood Shores",
+			schools:       models.Schools{Elementary: "Ellsworth", High: "Danbury"},
+			weight:        0.2,
+			expectedScore: 0.05,
+		},
+		{
+			name:          "unknown schools",
+			schools:       models.Schools{Elementary: "Per Board of Ed", Middle: "Per Board of Ed", High: "Per Board of Ed"},
+			weight:        0.2,
+			expectedScore: 0,
+		},
+		{
+			name:          "zero weight",
+			subdivision:   "Regency at Rivington",
+			weight:        0,
+			expectedScore: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			property := models.Property{Subdivision: tt.subdivision, Schools: tt.schools}
+
+			neighborhood := NewNeighborhood(property, subject, tt.weight)
+			score, err := neighborhood.Evaluate()
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !almostEqual(score, tt.expectedScore, 0.0001) {
+				t.Errorf("expected score %v, got %v", tt.expectedScore, score)
+			}
+		})
+	}
+
+	unknown := models.Property{Schools: models.Schools{High: "Per Board of Ed"}}
+	if score, _ := NewNeighborhood(unknown, unknown, 0.2).Evaluate(); score != 0 {
+		t.Errorf("expected unknown schools not to match, got score %v", score)
+	}
+}
@@ -1,6 +1,8 @@
 package criteria
 
 import (
+	"time"
+
 	"github.com/krlosmederos/locqube-challenge/pkg/models"
 )
 
@@ -15,6 +17,8 @@ type Recency struct {
 	Subject    models.Property
 	Weight     float64
 	TimeScores TimeScores
+	// AsOf is the date the age of the property is measured at, now when zero
+	AsOf time.Time
 }
 
 func NewRecency(property, subject models.Property, weight float64, timeScores TimeScores) *Recency {
@@ -27,7 +31,11 @@ func NewRecency(property, subject models.Property, weight float64, timeScores Ti
 }
 
 func (r *Recency) Evaluate() (float64, error) {
-	ageInMonths := r.Property.GetAgeInMonths()
+	asOf := r.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+	ageInMonths := r.Property.AgeInMonthsAt(asOf)
 	score := 0.0
 
 	switch {
@@ -92,3 +92,22 @@ func TestRecencyEvaluate(t *testing.T) {
 		})
 	}
 }
+
+func TestRecencyEvaluate_AsOf(t *testing.T) {
+	asOf := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	property := models.Property{
+		Status:                "Closed",
+		StatusChangeTimestamp: asOf.AddDate(0, -2, 0).Unix(),
+	}
+
+	recency := NewRecency(property, models.Property{}, 0.5, TimeScores{ThreeMonths: 1.0, SixMonths: 0.5, NineMonths: 0.25})
+	recency.AsOf = asOf
+	score, err := recency.Evaluate()
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !almostEqual(score, 0.5, 0.0001) {
+		t.Errorf("expected a sale two months before the date to score 0.5, got %v", score)
+	}
+}
@@ -1,6 +1,7 @@
 package criteria
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/krlosmederos/locqube-challenge/pkg/models"
@@ -21,6 +22,10 @@ func NewSize(property, subject models.Property, weight float64) *Size {
 }
 
 func (s *Size) Evaluate() (float64, error) {
+	if s.Subject.Size <= 0 {
+		return 0, fmt.Errorf("subject size must be greater than 0, got %v", s.Subject.Size)
+	}
+
 	sizeDiff := math.Abs(s.Property.Size-s.Subject.Size) / s.Subject.Size
 	score := 0.0
 
@@ -76,3 +76,11 @@ func TestSizeEvaluate(t *testing.T) {
 		})
 	}
 }
+
+func TestSizeEvaluate_ZeroSubjectSize(t *testing.T) {
+	size := NewSize(models.Property{Size: 2000}, models.Property{Size: 0}, 0.35)
+
+	if _, err := size.Evaluate(); err == nil {
+		t.Error("Evaluate() with a zero subject size expected an error")
+	}
+}
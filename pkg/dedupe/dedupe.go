@@ -0,0 +1,249 @@
+package dedupe
+
+import (
+	"math"
+	"sort"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+// saleWindow is the largest time between two closed records of a property
+// for them to be reported for the same sale, sales further apart are kept
+const saleWindow = 180 * 24 * 60 * 60
+
+// nearbyMiles is the distance under which two listings without a street
+// address are considered the same property
+const nearbyMiles = 0.01
+
+// Group is a property listed more than once
+type Group struct {
+	Key     string            `json:"key"`
+	Kept    []models.Property `json:"kept"`
+	Dropped []models.Property `json:"dropped"`
+}
+
+// Listings returns the listings without duplicates in their original order
+func Listings(listings []models.Property) []models.Property {
+	dropped := map[int]bool{}
+	for _, g := range group(listings) {
+		for _, i := range g.dropped {
+			dropped[i] = true
+		}
+	}
+	if len(dropped) == 0 {
+		return listings
+	}
+
+	result := make([]models.Property, 0, len(listings)-len(dropped))
+	for i, p := range listings {
+		if !dropped[i] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// Groups returns every property listed more than once and which of its
+// listings are kept
+func Groups(listings []models.Property) []Group {
+	var result []Group
+	for _, g := range group(listings) {
+		out := Group{Key: g.key}
+		for _, i := range g.kept {
+			out.Kept = append(out.Kept, listings[i])
+		}
+		for _, i := range g.dropped {
+			out.Dropped = append(out.Dropped, listings[i])
+		}
+		result = append(result, out)
+	}
+	return result
+}
+
+type indexGroup struct {
+	key     string
+	kept    []int
+	dropped []int
+}
+
+// group links the listings with the same address key, or close coordinates
+// when one of them has no street, and resolves every group of more than one
+func group(listings []models.Property) []indexGroup {
+	parent := make([]int, len(listings))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a, b int) {
+		if ra, rb := find(a), find(b); ra != rb {
+			parent[rb] = ra
+		}
+	}
+
+	keys := make([]string, len(listings))
+	first := map[string]int{}
+	cells := map[[2]int][]int{}
+	for i, p := range listings {
+		keys[i] = address.Key(p)
+		if keys[i] != "" {
+			if j, ok := first[keys[i]]; ok {
+				union(j, i)
+			} else {
+				first[keys[i]] = i
+			}
+		}
+		if hasCoordinates(p) {
+			cell := cellOf(p.Coordinates)
+			cells[cell] = append(cells[cell], i)
+		}
+	}
+
+	for i, p := range listings {
+		if keys[i] != "" || !hasCoordinates(p) {
+			continue
+		}
+		cell := cellOf(p.Coordinates)
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				for _, j := range cells[[2]int{cell[0] + dy, cell[1] + dx}] {
+					if j != i && models.DistanceMiles(p.Coordinates, listings[j].Coordinates) <= nearbyMiles {
+						union(j, i)
+					}
+				}
+			}
+		}
+	}
+
+	members := map[int][]int{}
+	var roots []int
+	for i := range listings {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], i)
+	}
+
+	var groups []indexGroup
+	for _, root := range roots {
+		indexes := members[root]
+		if len(indexes) < 2 {
+			continue
+		}
+
+		g := resolve(listings, indexes)
+		for _, i := range indexes {
+			if keys[i] != "" {
+				g.key = keys[i]
+				break
+			}
+		}
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// resolve keeps one record per sale of the property, the most authoritative
+// one, and the latest listing unless it ended in a kept sale
+func resolve(listings []models.Property, indexes []int) indexGroup {
+	sorted := append([]int(nil), indexes...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return moreAuthoritative(listings[sorted[a]], listings[sorted[b]])
+	})
+
+	var g indexGroup
+	var sales []int64
+	keptListing := false
+
+	for _, i := range sorted {
+		p := listings[i]
+		keep := false
+
+		if p.Status == "Closed" {
+			keep = true
+			for _, sold := range sales {
+				if abs(p.StatusChangeTimestamp-sold) <= saleWindow {
+					keep = false
+					break
+				}
+			}
+			if keep {
+				sales = append(sales, p.StatusChangeTimestamp)
+			}
+		} else if !keptListing {
+			keep = true
+			for _, sold := range sales {
+				if sold >= p.ListingDate {
+					keep = false
+					break
+				}
+			}
+			keptListing = keep
+		}
+
+		if keep {
+			g.kept = append(g.kept, i)
+		} else {
+			g.dropped = append(g.dropped, i)
+		}
+	}
+
+	sort.Ints(g.kept)
+	sort.Ints(g.dropped)
+	return g
+}
+
+// statusRank orders the statuses from the most to the least authoritative
+func statusRank(status string) int {
+	switch status {
+	case "Closed":
+		return 0
+	case "Under Contract":
+		return 1
+	case "Active":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// moreAuthoritative reports whether a is preferred over b: closed over
+// pending over active, then the latest modification
+func moreAuthoritative(a, b models.Property) bool {
+	if ra, rb := statusRank(a.Status), statusRank(b.Status); ra != rb {
+		return ra < rb
+	}
+	if a.ModifiedDate != b.ModifiedDate {
+		return a.ModifiedDate > b.ModifiedDate
+	}
+	if a.LastUpdated != b.LastUpdated {
+		return a.LastUpdated > b.LastUpdated
+	}
+	if a.StatusChangeTimestamp != b.StatusChangeTimestamp {
+		return a.StatusChangeTimestamp > b.StatusChangeTimestamp
+	}
+	return a.ID < b.ID
+}
+
+func hasCoordinates(p models.Property) bool {
+	return p.Coordinates.Latitude != 0 || p.Coordinates.Longitude != 0
+}
+
+// cellOf returns the grid cell of about 110m containing the coordinates
+func cellOf(c models.Coordinates) [2]int {
+	return [2]int{int(math.Floor(c.Latitude * 1000)), int(math.Floor(c.Longitude * 1000))}
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,172 @@
+package dedupe
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+const day = 24 * 60 * 60
+
+func listing(id, street, zip, status string, listed, changed, modified int64) models.Property {
+	return models.Property{
+		ID:                    id,
+		Address:               models.Address{City: "Danbury", Street: street, Zip: zip},
+		Status:                status,
+		ListingDate:           listed,
+		StatusChangeTimestamp: changed,
+		ModifiedDate:          modified,
+	}
+}
+
+func ids(listings []models.Property) []string {
+	result := []string{}
+	for _, p := range listings {
+		result = append(result, p.ID)
+	}
+	return result
+}
+
+func TestListings(t *testing.T) {
+	sold := int64(1700000000)
+
+	located := func(id string, lat, lon float64) models.Property {
+		p := listing(id, "", "06810", "Active", sold, sold, 0)
+		p.Coordinates = models.Coordinates{Latitude: lat, Longitude: lon}
+		return p
+	}
+
+	tests := []struct {
+		name     string
+		listings []models.Property
+		want     []string
+	}{
+		{
+			name: "distinct addresses",
+			listings: []models.Property{
+				listing("1", "12 Main St", "06810", "Closed", sold-30*day, sold, 0),
+				listing("2", "14 Main St", "06810", "Closed", sold-30*day, sold, 0),
+			},
+			want: []string{"1", "2"},
+		},
+		{
+			name: "same street in another zip",
+			listings: []models.Property{
+				listing("1", "12 Main St", "06810", "Closed", sold-30*day, sold, 0),
+				listing("2", "12 Main St", "06811", "Closed", sold-30*day, sold, 0),
+			},
+			want: []string{"1", "2"},
+		},
+		{
+			name: "cross-listed sale keeps the latest modification",
+			listings: []models.Property{
+				listing("1", "12 Main St", "06810", "Closed", sold-30*day, sold, sold+day),
+				listing("2", "12 MAIN ST.", "06810-1234", "Closed", sold-30*day, sold+day, sold+2*day),
+			},
+			want: []string{"2"},
+		},
+		{
+			name: "closed over active listing of the same sale",
+			listings: []models.Property{
+				listing("1", "12 Main St", "06810", "Active", sold-30*day, sold-30*day, sold+5*day),
+				listing("2", "12 Main St", "06810", "Closed", sold-30*day, sold, sold),
+			},
+			want: []string{"2"},
+		},
+		{
+			name: "relisted after the sale",
+			listings: []models.Property{
+				listing("1", "12 Main St", "06810", "Closed", sold-30*day, sold, sold),
+				listing("2", "12 Main St", "06810", "Active", sold+200*day, sold+200*day, sold+200*day),
+			},
+			want: []string{"1", "2"},
+		},
+		{
+			name: "sales a year apart",
+			listings: []models.Property{
+				listing("1", "12 Main St", "06810", "Closed", sold-30*day, sold, sold),
+				listing("2", "12 Main St", "06810", "Closed", sold+335*day, sold+365*day, sold+365*day),
+			},
+			want: []string{"1", "2"},
+		},
+		{
+			name: "expired and active listings keep the latest",
+			listings: []models.Property{
+				listing("1", "12 Main St", "06810", "Expired", sold-90*day, sold-30*day, sold-30*day),
+				listing("2", "12 Main St", "06810", "Active", sold, sold, sold),
+			},
+			want: []string{"2"},
+		},
+		{
+			name: "unit designators",
+			listings: []models.Property{
+				listing("1", "9 Thorncrest Ridge Unit Lot #52", "06810", "Closed", sold-30*day, sold, sold),
+				listing("2", "9 Thorncrest Rdg #52", "06810", "Closed", sold-30*day, sold, sold+day),
+				listing("3", "9 Thorncrest Rdg #53", "06810", "Closed", sold-30*day, sold, sold+day),
+			},
+			want: []string{"2", "3"},
+		},
+		{
+			name: "standardized address",
+			listings: []models.Property{
+				listing("1", "12 Main Street", "06810", "Closed", sold-30*day, sold, sold),
+				func() models.Property {
+					p := listing("2", "12 Main", "06810", "Closed", sold-30*day, sold, sold+day)
+					p.StdAddress = &models.Address{City: "Danbury", Zip: "06810", DeliveryLine: "12 Main St"}
+					return p
+				}(),
+			},
+			want: []string{"2"},
+		},
+		{
+			name: "listings without a street at the same coordinates",
+			listings: []models.Property{
+				located("1", 41.39401, -73.45402),
+				located("2", 41.39402, -73.45401),
+				located("3", 41.40000, -73.45401),
+			},
+			want: []string{"1", "3"},
+		},
+		{
+			name: "listings without a street or coordinates",
+			listings: []models.Property{
+				listing("1", "", "06810", "Closed", sold-30*day, sold, 0),
+				listing("2", "", "06810", "Closed", sold-30*day, sold, 0),
+			},
+			want: []string{"1", "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(Listings(tt.listings)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Listings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroups(t *testing.T) {
+	sold := int64(1700000000)
+	listings := []models.Property{
+		listing("1", "12 Main St", "06810", "Active", sold-30*day, sold-30*day, sold-30*day),
+		listing("2", "14 Main St", "06810", "Closed", sold-30*day, sold, sold),
+		listing("3", "12 Main St", "06810", "Closed", sold-30*day, sold, sold),
+	}
+
+	groups := Groups(listings)
+	if len(groups) != 1 {
+		t.Fatalf("Groups() returned %d groups, want 1", len(groups))
+	}
+	g := groups[0]
+	if g.Key != "12 MAIN ST|06810" {
+		t.Errorf("Key = %q, want %q", g.Key, "12 MAIN ST|06810")
+	}
+	if got := ids(g.Kept); !reflect.DeepEqual(got, []string{"3"}) {
+		t.Errorf("Kept = %v, want [3]", got)
+	}
+	if got := ids(g.Dropped); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Dropped = %v, want [1]", got)
+	}
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/algorithm"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+// WriteResultCSV writes the estimate of a valuation as a header row and one
+// row, the comparables are written by WriteCompGridCSV
+func WriteResultCSV(w io.Writer, subject models.Property, result algorithm.Result) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"id", "street", "city", "state", "zip", "value", "low", "high", "profile", "comparables"})
+	writer.Write([]string{
+		subject.ID,
+		subject.Address.Street,
+		subject.Address.City,
+		subject.Address.State,
+		subject.Address.Zip,
+		formatPrice(result.Value),
+		formatPrice(result.Low),
+		formatPrice(result.High),
+		result.Profile,
+		strconv.Itoa(len(result.Comparables)),
+	})
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteCompGridCSV writes the comp grid of a valuation: one column for the
+// subject and one per comparable in weight order, one row per attribute.
+// The subject has no price or weight, its estimate is in the value row.
+func WriteCompGridCSV(w io.Writer, subject models.Property, result algorithm.Result) error {
+	columns := len(result.Comparables) + 1
+	rows := []struct {
+		name    string
+		subject string
+		comp    func(c algorithm.Comparable) string
+	}{
+		{"id", subject.ID, func(c algorithm.Comparable) string { return c.ID }},
+		{"street", subject.Address.Street, func(c algorithm.Comparable) string { return c.Address.Street }},
+		{"city", subject.Address.City, func(c algorithm.Comparable) string { return c.Address.City }},
+		{"zip", subject.Address.Zip, func(c algorithm.Comparable) string { return c.Address.Zip }},
+		{"status", subject.Status, func(c algorithm.Comparable) string { return c.Status }},
+		{"size", formatNumber(subject.Size), func(c algorithm.Comparable) string { return formatNumber(c.Size) }},
+		{"beds", strconv.Itoa(subject.Beds), func(c algorithm.Comparable) string { return strconv.Itoa(c.Beds) }},
+		{"baths", formatNumber(subject.Baths.Total), func(c algorithm.Comparable) string { return formatNumber(c.Baths) }},
+		{"year_built", formatYear(subject.YearBuilt), func(c algorithm.Comparable) string { return formatYear(c.YearBuilt) }},
+		{"price", "", func(c algorithm.Comparable) string { return formatPrice(c.Price) }},
+		{"price_per_sqft", "", func(c algorithm.Comparable) string { return formatPricePerSqft(c.Price, c.Size) }},
+		{"weight", "", func(c algorithm.Comparable) string { return strconv.FormatFloat(c.Weight, 'f', 4, 64) }},
+		{"value", formatPrice(result.Value), func(c algorithm.Comparable) string { return "" }},
+	}
+
+	writer := csv.NewWriter(w)
+
+	header := make([]string, 0, columns+1)
+	header = append(header, "", "subject")
+	for i := range result.Comparables {
+		header = append(header, "comp "+strconv.Itoa(i+1))
+	}
+	writer.Write(header)
+
+	for _, row := range rows {
+		record := make([]string, 0, columns+1)
+		record = append(record, row.name, row.subject)
+		for _, c := range result.Comparables {
+			record = append(record, row.comp(c))
+		}
+		writer.Write(record)
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatPrice(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatYear(year int) string {
+	if year == 0 {
+		return ""
+	}
+	return strconv.Itoa(year)
+}
+
+func formatPricePerSqft(price, size float64) string {
+	if size <= 0 {
+		return ""
+	}
+	return formatPrice(price / size)
+}
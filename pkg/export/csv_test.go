@@ -0,0 +1,85 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/algorithm"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+func createTestResult() (models.Property, algorithm.Result) {
+	subject := models.Property{
+		ID:      "s",
+		Address: models.Address{Street: "1 Main St", City: "Danbury", State: "CT", Zip: "06810"},
+		Size:    2750,
+		Beds:    4,
+		Baths:   models.Bathroom{Total: 3.5},
+	}
+	result := algorithm.Result{
+		Value:   600000,
+		Low:     550000.5,
+		High:    649999.5,
+		Profile: "default",
+		Comparables: []algorithm.Comparable{
+			{ID: "a", Address: models.Address{Street: "2 Main St, Unit 1", City: "Danbury", Zip: "06810"}, Status: "Closed", Size: 2500, Beds: 4, Baths: 3, YearBuilt: 1990, Price: 500000, Weight: 0.95},
+			{ID: "b", Address: models.Address{City: "Danbury"}, Status: "Active", Beds: 3, Baths: 2.5, Price: 700000, Weight: 0.5},
+		},
+	}
+	return subject, result
+}
+
+func TestWriteResultCSV(t *testing.T) {
+	subject, result := createTestResult()
+
+	var buf bytes.Buffer
+	if err := WriteResultCSV(&buf, subject, result); err != nil {
+		t.Fatalf("WriteResultCSV() error = %v", err)
+	}
+
+	want := "id,street,city,state,zip,value,low,high,profile,comparables\n" +
+		"s,1 Main St,Danbury,CT,06810,600000.00,550000.50,649999.50,default,2\n"
+	if buf.String() != want {
+		t.Errorf("WriteResultCSV() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteCompGridCSV(t *testing.T) {
+	subject, result := createTestResult()
+
+	var buf bytes.Buffer
+	if err := WriteCompGridCSV(&buf, subject, result); err != nil {
+		t.Fatalf("WriteCompGridCSV() error = %v", err)
+	}
+
+	want := `,subject,comp 1,comp 2
+id,s,a,b
+street,1 Main St,"2 Main St, Unit 1",
+city,Danbury,Danbury,Danbury
+zip,06810,06810,
+status,,Closed,Active
+size,2750,2500,0
+beds,4,4,3
+baths,3.5,3,2.5
+year_built,,1990,
+price,,500000.00,700000.00
+price_per_sqft,,200.00,
+weight,,0.9500,0.5000
+value,600000.00,,
+`
+	if buf.String() != want {
+		t.Errorf("WriteCompGridCSV() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteCompGridCSV_NoComparables(t *testing.T) {
+	subject, _ := createTestResult()
+
+	var buf bytes.Buffer
+	if err := WriteCompGridCSV(&buf, subject, algorithm.Result{}); err != nil {
+		t.Fatalf("WriteCompGridCSV() error = %v", err)
+	}
+	if got := bytes.Count(buf.Bytes(), []byte("\n")); got != 14 {
+		t.Errorf("WriteCompGridCSV() wrote %d rows, want 14", got)
+	}
+}
@@ -3,14 +3,20 @@ package filters
 import (
 	"math"
 	"sort"
+	"time"
 
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
 	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/dedupe"
 	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
 )
 
 type PropertyFilter struct {
 	Subject models.Property
 	Config  *config.Config
+	// AsOf is the date the age of the sales is measured at, now when zero
+	AsOf time.Time
 }
 
 func NewPropertyFilter(subject models.Property, config *config.Config) *PropertyFilter {
@@ -23,29 +29,122 @@ func NewPropertyFilter(subject models.Property, config *config.Config) *Property
 // Filter returns a list of comparable properties based on the subject property
 func (f *PropertyFilter) Filter(listings []models.Property) []models.Property {
 	var comparableProperties []models.Property
+	subjectKey := address.Key(f.Subject)
 
 	for _, prop := range listings {
-		if !f.isSimilarProperty(prop) {
+		prop, ok := f.checkQuality(prop)
+		if !ok || !f.isSimilarProperty(prop) || f.isSubject(prop, subjectKey) {
 			continue
 		}
 
 		comparableProperties = append(comparableProperties, prop)
 	}
 
+	// a sale listed more than once, relisted or cross-listed, counts once
+	comparableProperties = dedupe.Listings(comparableProperties)
+	comparableProperties = f.restrictToSchoolDistrict(comparableProperties)
+
 	return f.sortByStatusAndRecency(comparableProperties)
 }
 
+// maxSizeDiff is the largest relative size difference of a comparable property
+const maxSizeDiff = 0.20
+
+// Query returns a store query selecting every listing Filter could keep,
+// sources apply it before the listings are filtered
+func (f *PropertyFilter) Query() store.Query {
+	q := store.Query{City: f.Subject.Address.City}
+	if f.Subject.Size > 0 {
+		// the size bounds are widened slightly so that rounding never drops a
+		// listing isSimilarProperty would keep
+		q.MinSize = f.Subject.Size * (1 - maxSizeDiff - 1e-9)
+		q.MaxSize = f.Subject.Size * (1 + maxSizeDiff + 1e-9)
+	}
+	return q
+}
+
+// FilterStore returns the same comparable properties as Filter but queries the
+// store indexes instead of scanning every listing
+func (f *PropertyFilter) FilterStore(s *store.Store) []models.Property {
+	return f.Filter(s.Query(f.Query()))
+}
+
+// checkQuality applies the data_quality.invalid setting to a listing and
+// reports whether it can be used
+func (f *PropertyFilter) checkQuality(prop models.Property) (models.Property, bool) {
+	switch f.Config.DataQuality.Invalid {
+	case config.DataQualityExclude:
+		return prop, !models.HasErrors(prop.Validate())
+	case config.DataQualityRepair:
+		prop.Repair()
+		return prop, !models.HasErrors(prop.Validate())
+	default:
+		return prop, true
+	}
+}
+
+// isSubject reports whether a listing is the subject itself or a sale of
+// the same address, which would price the subject against itself
+func (f *PropertyFilter) isSubject(prop models.Property, subjectKey string) bool {
+	if f.Subject.ID != "" && prop.ID == f.Subject.ID {
+		return true
+	}
+	if f.Subject.SystemID != "" && prop.SystemID == f.Subject.SystemID {
+		return true
+	}
+	return subjectKey != "" && address.Key(prop) == subjectKey
+}
+
+// restrictToSchoolDistrict keeps only the comparables in the subject's school
+// district when at least school_district.min_sales of them are closed sales
+func (f *PropertyFilter) restrictToSchoolDistrict(properties []models.Property) []models.Property {
+	if f.Config.SchoolDistrict.MinSales <= 0 {
+		return properties
+	}
+	district := f.Subject.SchoolDistrict()
+	if district == "" {
+		return properties
+	}
+
+	var inDistrict []models.Property
+	sales := 0
+	for _, prop := range properties {
+		if prop.SchoolDistrict() != district {
+			continue
+		}
+		inDistrict = append(inDistrict, prop)
+		if prop.Status == "Closed" {
+			sales++
+		}
+	}
+
+	if sales < f.Config.SchoolDistrict.MinSales {
+		return properties
+	}
+	return inDistrict
+}
+
 func (f *PropertyFilter) isSimilarProperty(prop models.Property) bool {
 	if prop.ListPrice == 0 && prop.SalePrice == 0 {
 		return false
 	}
 
-	if prop.Address.City != f.Subject.Address.City {
+	// rents and sale prices are never compared
+	if prop.IsLease() != (f.Config.ValuationType == config.ValuationRent) {
+		return false
+	}
+
+	// the size difference is relative to the subject's size
+	if f.Subject.Size <= 0 {
+		return false
+	}
+
+	if !address.SameCity(prop.Address.City, f.Subject.Address.City) {
 		return false
 	}
 
 	sizeDiff := math.Abs(prop.Size-f.Subject.Size) / f.Subject.Size
-	if sizeDiff > 0.20 {
+	if sizeDiff > maxSizeDiff {
 		return false
 	}
 
@@ -77,13 +176,24 @@ func (f *PropertyFilter) sortByStatusAndRecency(properties []models.Property) []
 }
 
 func (f *PropertyFilter) getMostRecentSoldProperties(soldProperties []models.Property) []models.Property {
-	sort.Slice(soldProperties, func(i, j int) bool {
-		return soldProperties[i].GetAgeInMonths() < soldProperties[j].GetAgeInMonths()
+	asOf := f.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	ages := make([]float64, len(soldProperties))
+	order := make([]int, len(soldProperties))
+	for i, p := range soldProperties {
+		ages[i] = p.AgeInMonthsAt(asOf)
+		order[i] = i
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		return ages[order[i]] < ages[order[j]]
 	})
 
 	var sales3M, sales6M, sales9M int
-	for _, p := range soldProperties {
-		age := p.GetAgeInMonths()
+	for _, age := range ages {
 		switch {
 		case age <= 3:
 			sales3M++
@@ -97,9 +207,9 @@ func (f *PropertyFilter) getMostRecentSoldProperties(soldProperties []models.Pro
 	maxAge := f.getMaxAgeForSales(sales3M, sales6M)
 
 	var result []models.Property
-	for _, p := range soldProperties {
-		if p.GetAgeInMonths() <= maxAge {
-			result = append(result, p)
+	for _, i := range order {
+		if ages[i] <= maxAge {
+			result = append(result, soldProperties[i])
 		}
 	}
 
@@ -0,0 +1,82 @@
+package filters
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+// createStatewideListings builds a synthetic feed spread over many cities
+func createStatewideListings(n int) []models.Property {
+	rng := rand.New(rand.NewSource(1))
+	now := time.Now().Unix()
+	month := int64(30 * 24 * 60 * 60)
+	statuses := []string{"Closed", "Closed", "Active", "Under Contract"}
+
+	listings := make([]models.Property, n)
+	for i := range listings {
+		age := now - int64(rng.Intn(12))*month
+		listings[i] = createTestProperty(
+			fmt.Sprintf("City %d", rng.Intn(150)),
+			1000+float64(rng.Intn(4000)),
+			2+rng.Intn(4),
+			1+float64(rng.Intn(7))*0.5,
+			statuses[rng.Intn(len(statuses))],
+			age,
+			age,
+		)
+		listings[i].ID = fmt.Sprint(i)
+	}
+	return listings
+}
+
+func TestPropertyFilter_FilterStoreMatchesFilter(t *testing.T) {
+	listings := createStatewideListings(20000)
+	s := store.New(listings)
+
+	for i := 0; i < 20; i++ {
+		subject := listings[i*97]
+		filter := NewPropertyFilter(subject, createTestConfig())
+
+		want := filter.Filter(listings)
+		got := filter.FilterStore(s)
+
+		if !reflect.DeepEqual(ids(got), ids(want)) {
+			t.Fatalf("FilterStore() for subject %s returned %d listings, want the %d of Filter()", subject.ID, len(got), len(want))
+		}
+	}
+}
+
+func ids(listings []models.Property) map[string]bool {
+	result := map[string]bool{}
+	for _, l := range listings {
+		result[l.ID] = true
+	}
+	return result
+}
+
+func BenchmarkPropertyFilter_Filter(b *testing.B) {
+	listings := createStatewideListings(200000)
+	filter := NewPropertyFilter(listings[0], createTestConfig())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		filter.Filter(listings)
+	}
+}
+
+func BenchmarkPropertyFilter_FilterStore(b *testing.B) {
+	listings := createStatewideListings(200000)
+	s := store.New(listings)
+	filter := NewPropertyFilter(listings[0], createTestConfig())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		filter.FilterStore(s)
+	}
+}
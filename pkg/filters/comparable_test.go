@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
 	"github.com/krlosmederos/locqube-challenge/pkg/config"
 	"github.com/krlosmederos/locqube-challenge/pkg/models"
 )
@@ -34,6 +35,9 @@ func createTestProperty(city string, size float64, beds int, baths float64, stat
 }
 
 func TestPropertyFilter_IsSimilarProperty(t *testing.T) {
+	address.SetDesignatedCities([]string{"Danbury"})
+	t.Cleanup(func() { address.SetDesignatedCities(nil) })
+
 	subject := createTestProperty("Danbury", 2000, 4, 2.5, "Active", 0, 0)
 	filter := NewPropertyFilter(subject, createTestConfig())
 
@@ -47,6 +51,11 @@ func TestPropertyFilter_IsSimilarProperty(t *testing.T) {
 			property: createTestProperty("Danbury", 2000, 4, 2.5, "Active", 0, 0),
 			want:     true,
 		},
+		{
+			name:     "city in another case and form",
+			property: createTestProperty("DANBURY CITY", 2000, 4, 2.5, "Active", 0, 0),
+			want:     true,
+		},
 		{
 			name:     "different city",
 			property: createTestProperty("Norwalk", 2000, 4, 2.5, "Active", 0, 0),
@@ -250,3 +259,155 @@ func TestPropertyFilter_Filter(t *testing.T) {
 		lastWasClosed = prop.Status == "Closed"
 	}
 }
+
+func TestPropertyFilter_DataQuality(t *testing.T) {
+	now := time.Now().Unix()
+	oneMonthAgo := now - (30 * 24 * 60 * 60)
+	subject := createTestProperty("Danbury", 2000, 4, 2.5, "Active", now, 0)
+
+	valid := createTestProperty("Danbury", 2000, 4, 2.5, "Closed", oneMonthAgo, oneMonthAgo)
+	swappedDates := createTestProperty("Danbury", 2000, 4, 2.5, "Closed", oneMonthAgo, oneMonthAgo-1000)
+	missingBathTotal := createTestProperty("Danbury", 2000, 4, 0, "Closed", oneMonthAgo, oneMonthAgo)
+	missingBathTotal.Baths.Full, missingBathTotal.Baths.Half = 2, 1
+	listings := []models.Property{valid, swappedDates, missingBathTotal}
+
+	tests := []struct {
+		action string
+		want   int
+	}{
+		{action: "", want: 2},
+		{action: config.DataQualityKeep, want: 2},
+		{action: config.DataQualityExclude, want: 1},
+		{action: config.DataQualityRepair, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.DataQuality.Invalid = tt.action
+
+			filtered := NewPropertyFilter(subject, cfg).Filter(listings)
+			if len(filtered) != tt.want {
+				t.Errorf("Filter() returned %d listings, want %d", len(filtered), tt.want)
+			}
+			for _, p := range filtered {
+				if tt.action == config.DataQualityRepair && len(p.Validate()) > 0 {
+					t.Errorf("Filter() returned an unrepaired listing: %v", p.Validate())
+				}
+			}
+		})
+	}
+}
+
+func TestPropertyFilter_ZeroSubjectSize(t *testing.T) {
+	subject := createTestProperty("Danbury", 0, 4, 2.5, "Active", time.Now().Unix(), 0)
+	filter := NewPropertyFilter(subject, createTestConfig())
+
+	listings := []models.Property{
+		createTestProperty("Danbury", 0, 4, 2.5, "Closed", 0, 0),
+		createTestProperty("Danbury", 2000, 4, 2.5, "Closed", 0, 0),
+	}
+	if filtered := filter.Filter(listings); len(filtered) != 0 {
+		t.Errorf("Filter() with a zero subject size returned %d listings, want 0", len(filtered))
+	}
+}
+
+func TestPropertyFilter_Duplicates(t *testing.T) {
+	now := time.Now().Unix()
+	oneMonthAgo := now - (30 * 24 * 60 * 60)
+	subject := createTestProperty("Danbury", 2000, 4, 2.5, "Active", now, 0)
+
+	sale := createTestProperty("Danbury", 2000, 4, 2.5, "Closed", oneMonthAgo-86400, oneMonthAgo)
+	sale.ID, sale.Address.Street, sale.Address.Zip = "1", "12 Main St", "06810"
+	crossListed := sale
+	crossListed.ID, crossListed.Address.Street = "2", "12 MAIN ST."
+	other := createTestProperty("Danbury", 2000, 4, 2.5, "Closed", oneMonthAgo-86400, oneMonthAgo)
+	other.ID, other.Address.Street, other.Address.Zip = "3", "14 Main St", "06810"
+
+	filtered := NewPropertyFilter(subject, createTestConfig()).Filter([]models.Property{sale, crossListed, other})
+	if len(filtered) != 2 {
+		t.Fatalf("Filter() returned %d listings, want 2", len(filtered))
+	}
+	for _, p := range filtered {
+		if p.ID == "2" {
+			t.Errorf("Filter() returned the cross-listed duplicate of listing 1")
+		}
+	}
+}
+
+func TestPropertyFilter_ValuationType(t *testing.T) {
+	subject := createTestProperty("Danbury", 2000, 4, 2.5, "Active", 0, 0)
+	sale := createTestProperty("Danbury", 2000, 4, 2.5, "Closed", 0, 0)
+	lease := createTestProperty("Danbury", 2000, 4, 2.5, "Closed", 0, 0)
+	lease.ListingType = "Residential Lease"
+
+	tests := []struct {
+		name          string
+		valuationType string
+		wantSale      bool
+		wantLease     bool
+	}{
+		{name: "default", valuationType: "", wantSale: true, wantLease: false},
+		{name: "sale", valuationType: config.ValuationSale, wantSale: true, wantLease: false},
+		{name: "rent", valuationType: config.ValuationRent, wantSale: false, wantLease: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.ValuationType = tt.valuationType
+			filter := NewPropertyFilter(subject, cfg)
+
+			if got := filter.isSimilarProperty(sale); got != tt.wantSale {
+				t.Errorf("isSimilarProperty(sale) = %v, want %v", got, tt.wantSale)
+			}
+			if got := filter.isSimilarProperty(lease); got != tt.wantLease {
+				t.Errorf("isSimilarProperty(lease) = %v, want %v", got, tt.wantLease)
+			}
+		})
+	}
+}
+
+func TestPropertyFilter_SchoolDistrict(t *testing.T) {
+	now := time.Now().Unix()
+	oneMonthAgo := now - (30 * 24 * 60 * 60)
+
+	subject := createTestProperty("Danbury", 2000, 4, 2.5, "Active", now, 0)
+	subject.Schools.High = "Danbury"
+
+	sale := func(id, high string) models.Property {
+		p := createTestProperty("Danbury", 2000, 4, 2.5, "Closed", oneMonthAgo-86400, oneMonthAgo)
+		p.ID, p.Address.Street, p.Schools.High = id, id+" Main St", high
+		return p
+	}
+	listings := []models.Property{
+		sale("1", "Danbury"),
+		sale("2", "DANBURY"),
+		sale("3", "Immaculate"),
+		sale("4", "Per Board of Ed"),
+	}
+
+	tests := []struct {
+		name     string
+		minSales int
+		subject  models.Property
+		want     int
+	}{
+		{name: "disabled", minSales: 0, subject: subject, want: 4},
+		{name: "enough sales in the district", minSales: 2, subject: subject, want: 2},
+		{name: "too few sales in the district", minSales: 3, subject: subject, want: 4},
+		{name: "subject district unknown", minSales: 1, subject: createTestProperty("Danbury", 2000, 4, 2.5, "Active", now, 0), want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.SchoolDistrict.MinSales = tt.minSales
+
+			filtered := NewPropertyFilter(tt.subject, cfg).Filter(listings)
+			if len(filtered) != tt.want {
+				t.Errorf("Filter() returned %d listings, want %d", len(filtered), tt.want)
+			}
+		})
+	}
+}
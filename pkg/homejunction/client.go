@@ -0,0 +1,293 @@
+package homejunction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+// SearchPath is the path of the listings search endpoint
+const SearchPath = "/ws/listings/search"
+
+// SearchParams selects the listings of a search, zero fields do not filter
+type SearchParams struct {
+	Market string
+	City   string
+	Zip    string
+
+	// Near and RadiusMiles select the listings within a distance of a point
+	Near        *models.Coordinates
+	RadiusMiles float64
+
+	Statuses []string
+
+	// ChangedAfter and ChangedBefore bound the status change timestamp (inclusive)
+	ChangedAfter  int64
+	ChangedBefore int64
+}
+
+// SearchResponse is the body returned by the search endpoint
+type SearchResponse struct {
+	Success bool `json:"success"`
+	Result  struct {
+		Total    int               `json:"total"`
+		Listings []json.RawMessage `json:"listings"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Client queries the listings search endpoint of a HomeJunction style API
+type Client struct {
+	BaseURL string
+	Token   string
+	// Market is searched by Listings
+	Market string
+
+	HTTP     *http.Client
+	PageSize int
+
+	// MaxRetries is the number of times a request failing with a network
+	// error, 429 or 5xx is retried, waiting Backoff and doubling it each time
+	MaxRetries int
+	Backoff    time.Duration
+
+	limiter *limiter
+}
+
+// NewClient creates a client that sends at most requestsPerSecond requests,
+// no limit is applied when it is 0
+func NewClient(baseURL, token string, requestsPerSecond float64) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Token:      token,
+		HTTP:       &http.Client{Timeout: 30 * time.Second},
+		PageSize:   100,
+		MaxRetries: 3,
+		Backoff:    500 * time.Millisecond,
+		limiter:    newLimiter(requestsPerSecond),
+	}
+}
+
+// Search returns every listing matching the parameters, following the
+// pages of the result until the total is reached
+func (c *Client) Search(ctx context.Context, params SearchParams) ([]models.Property, error) {
+	var listings []models.Property
+
+	for page := 1; ; page++ {
+		resp, err := c.searchPage(ctx, params, page)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, raw := range resp.Result.Listings {
+			var p models.Property
+			if err := json.Unmarshal(raw, &p); err != nil {
+				return nil, fmt.Errorf("page %d listing %d: %v", page, i+1, err)
+			}
+			listings = append(listings, p)
+		}
+
+		if len(resp.Result.Listings) == 0 || len(listings) >= resp.Result.Total {
+			return listings, nil
+		}
+	}
+}
+
+// Listings searches the client's market, it implements source.ListingSource.
+// The endpoint matches the exact city name, so the city is left out of the
+// search and matched on the client, where "Danbury Town" is Danbury.
+func (c *Client) Listings(q store.Query) ([]models.Property, error) {
+	listings, err := c.Search(context.Background(), SearchParams{
+		Market:        c.Market,
+		Zip:           q.Zip,
+		Near:          q.Near,
+		RadiusMiles:   q.RadiusMiles,
+		Statuses:      q.Statuses,
+		ChangedAfter:  q.SoldAfter,
+		ChangedBefore: q.SoldBefore,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the endpoint cannot filter by size or match the spellings of a city
+	result := listings[:0]
+	for _, p := range listings {
+		if q.Matches(p) {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (c *Client) searchPage(ctx context.Context, params SearchParams, page int) (*SearchResponse, error) {
+	values := params.values()
+	values.Set("pageNumber", strconv.Itoa(page))
+	values.Set("pageSize", strconv.Itoa(c.PageSize))
+	endpoint := c.BaseURL + SearchPath + "?" + values.Encode()
+
+	backoff := c.Backoff
+	for attempt := 0; ; attempt++ {
+		resp, retryAfter, err := c.get(ctx, endpoint)
+		if err == nil {
+			return resp, nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) || attempt >= c.MaxRetries {
+			return nil, err
+		}
+
+		wait := backoff
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// permanentError is a failed request that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+// get sends one request, the returned duration is the Retry-After the server asked for
+func (c *Client) get(ctx context.Context, endpoint string) (*SearchResponse, time.Duration, error) {
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, 0, &permanentError{err}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, &permanentError{err}
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpResp, err := c.HTTP.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, 0, &permanentError{ctx.Err()}
+		}
+		return nil, 0, fmt.Errorf("listings search failed: %v", err)
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listings search failed: %v", err)
+	}
+
+	if httpResp.StatusCode == http.StatusTooManyRequests || httpResp.StatusCode >= 500 {
+		return nil, retryAfter(httpResp.Header), fmt.Errorf("listings search failed: %s", httpResp.Status)
+	}
+
+	var resp SearchResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, 0, &permanentError{fmt.Errorf("invalid listings search response: %v", err)}
+	}
+	if httpResp.StatusCode != http.StatusOK || !resp.Success {
+		message := httpResp.Status
+		if resp.Error != nil && resp.Error.Message != "" {
+			message = resp.Error.Message
+		}
+		return nil, 0, &permanentError{fmt.Errorf("listings search failed: %s", message)}
+	}
+
+	return &resp, 0, nil
+}
+
+func (p SearchParams) values() url.Values {
+	values := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	set("market", p.Market)
+	set("city", p.City)
+	set("zip", p.Zip)
+	set("status", strings.Join(p.Statuses, ","))
+	if p.Near != nil && p.RadiusMiles > 0 {
+		set("circle", fmt.Sprintf("%g,%g,%g", p.Near.Latitude, p.Near.Longitude, p.RadiusMiles))
+	}
+	if p.ChangedAfter > 0 {
+		set("changedAfter", strconv.FormatInt(p.ChangedAfter, 10))
+	}
+	if p.ChangedBefore > 0 {
+		set("changedBefore", strconv.FormatInt(p.ChangedBefore, 10))
+	}
+
+	return values
+}
+
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// limiter spaces requests evenly so that at most a rate per second are sent
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newLimiter(perSecond float64) *limiter {
+	if perSecond <= 0 {
+		return &limiter{}
+	}
+	return &limiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil || l.interval == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	return sleep(ctx, at.Sub(now))
+}
@@ -0,0 +1,232 @@
+package homejunction_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+	"github.com/krlosmederos/locqube-challenge/pkg/algorithm"
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/homejunction"
+	"github.com/krlosmederos/locqube-challenge/pkg/homejunction/hjtest"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/source"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+const fixturePath = "../../data/market_listings_response.json"
+
+func startServer(t *testing.T) *hjtest.Server {
+	t.Helper()
+	server, err := hjtest.NewServer(fixturePath)
+	if err != nil {
+		t.Fatalf("hjtest.NewServer() error = %v", err)
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(server *hjtest.Server) *homejunction.Client {
+	client := homejunction.NewClient(server.URL, hjtest.Token, 0)
+	client.Market = "smart"
+	client.PageSize = 5
+	client.Backoff = time.Millisecond
+	return client
+}
+
+func TestClient_Search(t *testing.T) {
+	server := startServer(t)
+
+	tests := []struct {
+		name     string
+		params   homejunction.SearchParams
+		want     int
+		requests int
+	}{
+		{name: "market", params: homejunction.SearchParams{Market: "smart"}, want: 19, requests: 4},
+		{name: "city and status", params: homejunction.SearchParams{Market: "smart", City: "Danbury", Statuses: []string{"Active", "Under Contract"}}, want: 2, requests: 1},
+		{name: "zip", params: homejunction.SearchParams{Zip: "06811", Statuses: []string{"Closed"}}, want: 15, requests: 3},
+		{name: "radius", params: homejunction.SearchParams{Near: &models.Coordinates{Latitude: 41.378204, Longitude: -73.471196}, RadiusMiles: 0.1}, want: 1, requests: 1},
+		{name: "status change window", params: homejunction.SearchParams{ChangedAfter: 1, ChangedBefore: 2}, want: 0, requests: 1},
+		{name: "other market", params: homejunction.SearchParams{Market: "other"}, want: 0, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(server)
+			before := server.Requests()
+
+			listings, err := client.Search(context.Background(), tt.params)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if len(listings) != tt.want {
+				t.Errorf("Search() returned %d listings, want %d", len(listings), tt.want)
+			}
+			if got := server.Requests() - before; got != tt.requests {
+				t.Errorf("Search() sent %d requests, want %d", got, tt.requests)
+			}
+		})
+	}
+}
+
+func TestClient_SearchMapsListings(t *testing.T) {
+	client := newTestClient(startServer(t))
+
+	listings, err := client.Search(context.Background(), homejunction.SearchParams{Market: "smart"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	file, err := source.All(&source.File{Path: fixturePath})
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	if !reflect.DeepEqual(listings, file) {
+		t.Error("Search() listings differ from the fixture")
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   []int
+		maxRetries int
+		wantErr    bool
+		requests   int
+	}{
+		{name: "server errors and rate limit", failures: []int{503, 429}, maxRetries: 3, requests: 3},
+		{name: "retries exhausted", failures: []int{500, 502}, maxRetries: 1, wantErr: true, requests: 2},
+		{name: "client error is not retried", failures: []int{400}, maxRetries: 3, wantErr: true, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startServer(t)
+			server.FailNext(tt.failures...)
+
+			client := newTestClient(server)
+			client.PageSize = 100
+			client.MaxRetries = tt.maxRetries
+
+			_, err := client.Search(context.Background(), homejunction.SearchParams{Market: "smart"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Search() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if server.Requests() != tt.requests {
+				t.Errorf("Search() sent %d requests, want %d", server.Requests(), tt.requests)
+			}
+		})
+	}
+}
+
+func TestClient_InvalidToken(t *testing.T) {
+	server := startServer(t)
+	client := homejunction.NewClient(server.URL, "wrong", 0)
+
+	if _, err := client.Search(context.Background(), homejunction.SearchParams{}); err == nil {
+		t.Error("Search() with an invalid token expected an error")
+	}
+	if server.Requests() != 1 {
+		t.Errorf("Search() sent %d requests, want 1", server.Requests())
+	}
+}
+
+func TestClient_RateLimit(t *testing.T) {
+	server := startServer(t)
+	client := homejunction.NewClient(server.URL, hjtest.Token, 50)
+	client.PageSize = 5
+
+	start := time.Now()
+	if _, err := client.Search(context.Background(), homejunction.SearchParams{}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	// 4 pages at 50 requests per second are at least 3 intervals of 20ms apart
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("Search() took %v, want at least 60ms", elapsed)
+	}
+}
+
+func TestClient_ContextCanceled(t *testing.T) {
+	server := startServer(t)
+	server.FailNext(http.StatusServiceUnavailable)
+
+	client := newTestClient(server)
+	client.Backoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.Search(ctx, homejunction.SearchParams{}); err == nil {
+		t.Error("Search() expected an error when the context is done during backoff")
+	}
+}
+
+func TestClient_Valuation(t *testing.T) {
+	client := newTestClient(startServer(t))
+
+	cfg := config.Defaults()
+	cfg.MinSalesCount = 1
+	subject := models.Property{
+		PropertyType: "Single-family home",
+		Beds:         4,
+		Baths:        models.Bathroom{Total: 3.5},
+		Size:         2750,
+		Address:      models.Address{City: "Danbury", State: "CT"},
+	}
+
+	valuation, err := algorithm.NewValuationWithSource(subject, client, cfg)
+	if err != nil {
+		t.Fatalf("NewValuationWithSource() error = %v", err)
+	}
+	if len(valuation.Listings) == 0 {
+		t.Fatal("the client returned no listings in the subject's size range")
+	}
+
+	file, _ := source.All(&source.File{Path: fixturePath})
+	want := algorithm.NewValuationWithConfig(subject, file, cfg).Estimate()
+	if got := valuation.Estimate(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Estimate() = %+v, want the file based estimate %+v", got, want)
+	}
+}
+
+func TestClient_ListingsOfDesignatedCity(t *testing.T) {
+	address.SetDesignatedCities([]string{"Danbury"})
+	t.Cleanup(func() { address.SetDesignatedCities(nil) })
+
+	// S-3003 of the rental fixture is in "Danbury Town"
+	server, err := hjtest.NewServer("../../data/rental_listings.json")
+	if err != nil {
+		t.Fatalf("hjtest.NewServer() error = %v", err)
+	}
+	t.Cleanup(server.Close)
+	client := newTestClient(server)
+	client.Market = ""
+
+	listings, err := client.Listings(store.Query{City: "Danbury", Statuses: []string{"Closed"}})
+	if err != nil {
+		t.Fatalf("Listings() error = %v", err)
+	}
+	var ids []string
+	for _, l := range listings {
+		if !strings.HasPrefix(l.ID, "R-") {
+			ids = append(ids, l.ID)
+		}
+	}
+	if want := []string{"S-3001", "S-3002", "S-3003", "S-3004"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Listings(Danbury) returned the sales %v, want %v", ids, want)
+	}
+
+	search, err := client.Search(context.Background(), homejunction.SearchParams{City: "Danbury Town"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(search) != 11 {
+		t.Errorf("Search(Danbury Town) returned %d listings, want the 11 of Danbury", len(search))
+	}
+}
@@ -0,0 +1,251 @@
+// Package hjtest provides a fake listings search API serving fixtures so
+// that the client and valuations using it can be tested offline
+package hjtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+	"github.com/krlosmederos/locqube-challenge/pkg/homejunction"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+// Token is the bearer token the fake server accepts
+const Token = "test-token"
+
+type listing struct {
+	raw      json.RawMessage
+	market   string
+	property models.Property
+}
+
+// Server is a fake listings search API backed by fixture listings. Failures
+// can be injected to exercise retries.
+type Server struct {
+	*httptest.Server
+
+	listings []listing
+
+	mu       sync.Mutex
+	requests int
+	failures []int
+}
+
+// NewServer starts a server serving the listings of a JSON array fixture
+// such as data/market_listings_response.json, the fixture's fields are
+// served unchanged
+func NewServer(fixturePath string) (*Server, error) {
+	data, err := os.ReadFile(fixturePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, fmt.Errorf("error parsing fixture %s: %v", fixturePath, err)
+	}
+
+	s := &Server{}
+	for i, raw := range raws {
+		l := listing{raw: raw}
+		var meta struct {
+			Market string `json:"market"`
+		}
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return nil, fmt.Errorf("fixture listing %d: %v", i+1, err)
+		}
+		if err := json.Unmarshal(raw, &l.property); err != nil {
+			return nil, fmt.Errorf("fixture listing %d: %v", i+1, err)
+		}
+		l.market = meta.Market
+		s.listings = append(s.listings, l)
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s, nil
+}
+
+// FailNext makes the next requests fail with the given statuses, one per request
+func (s *Server) FailNext(statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, statuses...)
+}
+
+// Requests returns the number of requests received
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	var failure int
+	if len(s.failures) > 0 {
+		failure, s.failures = s.failures[0], s.failures[1:]
+	}
+	s.mu.Unlock()
+
+	if failure != 0 {
+		if failure == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0")
+		}
+		writeError(w, failure, http.StatusText(failure))
+		return
+	}
+
+	if r.URL.Path != homejunction.SearchPath {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+Token {
+		writeError(w, http.StatusUnauthorized, "invalid token")
+		return
+	}
+
+	query := r.URL.Query()
+	match, err := matcher(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, pageSize := 1, 100
+	if v := query.Get("pageNumber"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			writeError(w, http.StatusBadRequest, "invalid pageNumber")
+			return
+		}
+	}
+	if v := query.Get("pageSize"); v != "" {
+		if pageSize, err = strconv.Atoi(v); err != nil || pageSize < 1 {
+			writeError(w, http.StatusBadRequest, "invalid pageSize")
+			return
+		}
+	}
+
+	var matches []json.RawMessage
+	for _, l := range s.listings {
+		if match(l) {
+			matches = append(matches, l.raw)
+		}
+	}
+
+	var resp homejunction.SearchResponse
+	resp.Success = true
+	resp.Result.Total = len(matches)
+	resp.Result.Listings = []json.RawMessage{}
+	if start := (page - 1) * pageSize; start < len(matches) {
+		end := min(start+pageSize, len(matches))
+		resp.Result.Listings = matches[start:end]
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// matcher builds the predicate of the search parameters
+func matcher(query map[string][]string) (func(listing) bool, error) {
+	get := func(key string) string {
+		if v := query[key]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+
+	var conditions []func(listing) bool
+	equalFold := func(value string, field func(listing) string) {
+		if value != "" {
+			conditions = append(conditions, func(l listing) bool { return strings.EqualFold(field(l), value) })
+		}
+	}
+
+	equalFold(get("market"), func(l listing) string { return l.market })
+	if city := get("city"); city != "" {
+		conditions = append(conditions, func(l listing) bool { return address.SameCity(l.property.Address.City, city) })
+	}
+	equalFold(get("zip"), func(l listing) string { return l.property.Address.Zip })
+
+	if v := get("status"); v != "" {
+		statuses := strings.Split(v, ",")
+		conditions = append(conditions, func(l listing) bool {
+			for _, s := range statuses {
+				if strings.EqualFold(s, l.property.Status) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	if v := get("changedAfter"); v != "" {
+		bound, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid changedAfter")
+		}
+		conditions = append(conditions, func(l listing) bool { return l.property.StatusChangeTimestamp >= bound })
+	}
+	if v := get("changedBefore"); v != "" {
+		bound, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid changedBefore")
+		}
+		conditions = append(conditions, func(l listing) bool { return l.property.StatusChangeTimestamp <= bound })
+	}
+
+	if v := get("circle"); v != "" {
+		lat, lon, radius, err := parseCircle(v)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, func(l listing) bool {
+			center := models.Coordinates{Latitude: lat, Longitude: lon}
+			return models.DistanceMiles(center, l.property.Coordinates) <= radius
+		})
+	}
+
+	return func(l listing) bool {
+		for _, c := range conditions {
+			if !c(l) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func parseCircle(v string) (lat, lon, radius float64, err error) {
+	parts := strings.Split(v, ",")
+	if len(parts) != 3 {
+		return 0, 0, 0, fmt.Errorf("invalid circle %q", v)
+	}
+
+	values := make([]float64, 3)
+	for i, p := range parts {
+		if values[i], err = strconv.ParseFloat(p, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid circle %q", v)
+		}
+	}
+	return values[0], values[1], values[2], nil
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"success": false,
+		"error":   map[string]string{"message": message},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
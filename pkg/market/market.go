@@ -0,0 +1,220 @@
+package market
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+	"github.com/krlosmederos/locqube-challenge/pkg/dedupe"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+// Values of Options.GroupBy
+const (
+	GroupByNone         = ""
+	GroupByCity         = "city"
+	GroupByZip          = "zip"
+	GroupByPropertyType = "property_type"
+)
+
+const day = 24 * 60 * 60
+
+// Options selects how listings are grouped and the period the sales are
+// counted over, the period is the Months calendar months ending with AsOf.
+// Rentals computes the statistics of the lease listings, whose prices are
+// monthly rents, instead of the sales.
+type Options struct {
+	GroupBy string
+	Months  int
+	AsOf    time.Time
+	Rentals bool
+}
+
+// Stats summarizes the market of a group of listings. The sales figures are
+// those of the closed sales of the period, the inventory is the listings
+// still active.
+type Stats struct {
+	Group               string   `json:"group"`
+	Active              int      `json:"active"`
+	Pending             int      `json:"pending"`
+	Sales               int      `json:"sales"`
+	MedianSalePrice     float64  `json:"medianSalePrice"`
+	MedianPricePerSqft  float64  `json:"medianPricePerSqft"`
+	SaleToListRatio     float64  `json:"saleToListRatio"`
+	AverageDaysOnMarket float64  `json:"averageDaysOnMarket"`
+	AbsorptionRate      float64  `json:"absorptionRate"`
+	MonthsOfInventory   float64  `json:"monthsOfInventory"`
+	Trend               []Period `json:"trend"`
+}
+
+// Period holds the sales of one calendar month
+type Period struct {
+	Month              string  `json:"month"`
+	Sales              int     `json:"sales"`
+	MedianSalePrice    float64 `json:"medianSalePrice"`
+	MedianPricePerSqft float64 `json:"medianPricePerSqft"`
+	SaleToListRatio    float64 `json:"saleToListRatio"`
+}
+
+// Compute returns the statistics of every group of listings in group order.
+// Duplicate listings of a sale are counted once.
+func Compute(listings []models.Property, opts Options) []Stats {
+	if opts.Months <= 0 {
+		opts.Months = 6
+	}
+	if opts.AsOf.IsZero() {
+		opts.AsOf = time.Now()
+	}
+
+	groups := map[string][]models.Property{}
+	for _, p := range dedupe.Listings(listings) {
+		if p.IsLease() != opts.Rentals {
+			continue
+		}
+		key := groupKey(p, opts.GroupBy)
+		groups[key] = append(groups[key], p)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]Stats, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, compute(key, groups[key], opts))
+	}
+	return result
+}
+
+func groupKey(p models.Property, groupBy string) string {
+	switch groupBy {
+	case GroupByCity:
+		return address.City(p.Address.City)
+	case GroupByZip:
+		zip := p.Address.Zip
+		if len(zip) > 5 {
+			zip = zip[:5]
+		}
+		return zip
+	case GroupByPropertyType:
+		return strings.TrimSpace(p.PropertyType)
+	default:
+		return "all"
+	}
+}
+
+func compute(group string, listings []models.Property, opts Options) Stats {
+	stats := Stats{Group: group}
+
+	end := opts.AsOf.UTC()
+	firstMonth := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1-opts.Months, 0)
+	months := make([][]models.Property, opts.Months)
+
+	var sales []models.Property
+	for _, p := range listings {
+		switch p.Status {
+		case "Active":
+			stats.Active++
+		case "Under Contract", "Pending":
+			stats.Pending++
+		case "Closed":
+			sold := time.Unix(p.StatusChangeTimestamp, 0).UTC()
+			if sold.Before(firstMonth) || sold.After(end) {
+				continue
+			}
+			sales = append(sales, p)
+			i := (sold.Year()-firstMonth.Year())*12 + int(sold.Month()-firstMonth.Month())
+			months[i] = append(months[i], p)
+		}
+	}
+
+	stats.Sales = len(sales)
+	stats.MedianSalePrice = median(sales, salePrice)
+	stats.MedianPricePerSqft = median(sales, pricePerSqft)
+	stats.SaleToListRatio = median(sales, saleToList)
+	stats.AverageDaysOnMarket = average(sales, daysOnMarket)
+	stats.AbsorptionRate = float64(len(sales)) / float64(opts.Months)
+	if stats.AbsorptionRate > 0 {
+		stats.MonthsOfInventory = float64(stats.Active) / stats.AbsorptionRate
+	}
+
+	for i, sold := range months {
+		stats.Trend = append(stats.Trend, Period{
+			Month:              firstMonth.AddDate(0, i, 0).Format("2006-01"),
+			Sales:              len(sold),
+			MedianSalePrice:    median(sold, salePrice),
+			MedianPricePerSqft: median(sold, pricePerSqft),
+			SaleToListRatio:    median(sold, saleToList),
+		})
+	}
+	return stats
+}
+
+// the measures return false when a listing does not have the fields they need
+
+func salePrice(p models.Property) (float64, bool) {
+	return p.SalePrice, p.SalePrice > 0
+}
+
+func pricePerSqft(p models.Property) (float64, bool) {
+	if p.SalePrice <= 0 || p.Size <= 0 {
+		return 0, false
+	}
+	return p.SalePrice / p.Size, true
+}
+
+func saleToList(p models.Property) (float64, bool) {
+	if p.SalePrice <= 0 || p.ListPrice <= 0 {
+		return 0, false
+	}
+	return p.SalePrice / p.ListPrice, true
+}
+
+// daysOnMarket returns the days on market of the feed, or the days from the
+// listing date to the sale
+func daysOnMarket(p models.Property) (float64, bool) {
+	if p.DaysOnMarket > 0 {
+		return float64(p.DaysOnMarket), true
+	}
+	if p.ListingDate <= 0 || p.StatusChangeTimestamp < p.ListingDate {
+		return 0, false
+	}
+	return float64(p.StatusChangeTimestamp-p.ListingDate) / day, true
+}
+
+func median(listings []models.Property, measure func(models.Property) (float64, bool)) float64 {
+	var values []float64
+	for _, p := range listings {
+		if v, ok := measure(p); ok {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return 0
+	}
+
+	sort.Float64s(values)
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+	return values[middle]
+}
+
+func average(listings []models.Property, measure func(models.Property) (float64, bool)) float64 {
+	var sum float64
+	var n int
+	for _, p := range listings {
+		if v, ok := measure(p); ok {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
@@ -0,0 +1,155 @@
+package market
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+func sale(id, city, zip string, sold time.Time, list, price, size float64, dom int) models.Property {
+	return models.Property{
+		ID:                    id,
+		Address:               models.Address{City: city, Zip: zip, Street: id + " Main St"},
+		Status:                "Closed",
+		ListPrice:             list,
+		SalePrice:             price,
+		Size:                  size,
+		ListingDate:           sold.Unix() - int64(dom)*day,
+		StatusChangeTimestamp: sold.Unix(),
+		PropertyType:          "Single Family",
+	}
+}
+
+func TestCompute(t *testing.T) {
+	address.SetDesignatedCities([]string{"Danbury"})
+	t.Cleanup(func() { address.SetDesignatedCities(nil) })
+
+	asOf := time.Date(2024, 6, 30, 23, 59, 59, 0, time.UTC)
+	month := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 12, 0, 0, 0, time.UTC) }
+
+	active := models.Property{ID: "a1", Address: models.Address{City: "Danbury", Zip: "06810"}, Status: "Active", ListPrice: 600000}
+	pending := models.Property{ID: "p1", Address: models.Address{City: "Danbury", Zip: "06811"}, Status: "Under Contract", ListPrice: 650000}
+	listings := []models.Property{
+		sale("1", "Danbury", "06810", month(4, 10), 500000, 490000, 2000, 20),
+		sale("2", "DANBURY", "06811", month(5, 3), 600000, 600000, 2500, 40),
+		sale("3", "Danbury City", "06810", month(6, 20), 400000, 380000, 1900, 30),
+		sale("4", "Danbury", "06810", month(1, 5), 900000, 900000, 3000, 10),
+		sale("5", "Bethel", "06801", month(6, 1), 700000, 710000, 2800, 5),
+		active, pending,
+	}
+	// a cross-listed copy of sale 3 is counted once
+	duplicate := listings[2]
+	duplicate.ID = "3b"
+	listings = append(listings, duplicate)
+
+	stats := Compute(listings, Options{GroupBy: GroupByCity, Months: 3, AsOf: asOf})
+	if len(stats) != 2 || stats[0].Group != "BETHEL" || stats[1].Group != "DANBURY" {
+		t.Fatalf("groups = %+v, want BETHEL and DANBURY", stats)
+	}
+
+	danbury := stats[1]
+	if danbury.Active != 1 || danbury.Pending != 1 || danbury.Sales != 3 {
+		t.Errorf("active, pending, sales = %d, %d, %d, want 1, 1, 3", danbury.Active, danbury.Pending, danbury.Sales)
+	}
+	if danbury.MedianSalePrice != 490000 {
+		t.Errorf("MedianSalePrice = %v, want 490000", danbury.MedianSalePrice)
+	}
+	if danbury.MedianPricePerSqft != 240 {
+		t.Errorf("MedianPricePerSqft = %v, want 240", danbury.MedianPricePerSqft)
+	}
+	if danbury.SaleToListRatio != 0.98 {
+		t.Errorf("SaleToListRatio = %v, want 0.98", danbury.SaleToListRatio)
+	}
+	if danbury.AverageDaysOnMarket != 30 {
+		t.Errorf("AverageDaysOnMarket = %v, want 30", danbury.AverageDaysOnMarket)
+	}
+	if danbury.AbsorptionRate != 1 || danbury.MonthsOfInventory != 1 {
+		t.Errorf("AbsorptionRate, MonthsOfInventory = %v, %v, want 1, 1", danbury.AbsorptionRate, danbury.MonthsOfInventory)
+	}
+
+	var months []string
+	for _, p := range danbury.Trend {
+		months = append(months, p.Month)
+		if p.Sales != 1 {
+			t.Errorf("%s sales = %d, want 1", p.Month, p.Sales)
+		}
+	}
+	if !reflect.DeepEqual(months, []string{"2024-04", "2024-05", "2024-06"}) {
+		t.Errorf("trend months = %v, want 2024-04 to 2024-06", months)
+	}
+	if danbury.Trend[2].MedianSalePrice != 380000 {
+		t.Errorf("2024-06 median = %v, want 380000", danbury.Trend[2].MedianSalePrice)
+	}
+}
+
+func TestCompute_GroupBy(t *testing.T) {
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	sold := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	condo := sale("2", "Danbury", "06811-2000", sold, 300000, 300000, 1000, 10)
+	condo.PropertyType = "Condominium"
+	listings := []models.Property{
+		sale("1", "Danbury", "06810", sold, 500000, 500000, 2000, 10),
+		condo,
+		sale("3", "Bethel", "06801", sold, 400000, 400000, 2000, 10),
+	}
+
+	tests := []struct {
+		groupBy string
+		want    []string
+	}{
+		{groupBy: GroupByNone, want: []string{"all"}},
+		{groupBy: GroupByZip, want: []string{"06801", "06810", "06811"}},
+		{groupBy: GroupByPropertyType, want: []string{"Condominium", "Single Family"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.groupBy, func(t *testing.T) {
+			var groups []string
+			for _, s := range Compute(listings, Options{GroupBy: tt.groupBy, Months: 1, AsOf: asOf}) {
+				groups = append(groups, s.Group)
+			}
+			if !reflect.DeepEqual(groups, tt.want) {
+				t.Errorf("groups = %v, want %v", groups, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompute_NoSales(t *testing.T) {
+	listings := []models.Property{{ID: "a", Address: models.Address{City: "Danbury"}, Status: "Active", ListPrice: 500000}}
+
+	stats := Compute(listings, Options{GroupBy: GroupByCity, Months: 2, AsOf: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)})
+	if len(stats) != 1 {
+		t.Fatalf("got %d groups, want 1", len(stats))
+	}
+	s := stats[0]
+	if s.Sales != 0 || s.MedianSalePrice != 0 || s.AbsorptionRate != 0 || s.MonthsOfInventory != 0 || math.IsNaN(s.AverageDaysOnMarket) {
+		t.Errorf("stats = %+v, want no sales figures", s)
+	}
+	if len(s.Trend) != 2 {
+		t.Errorf("got %d trend periods, want 2", len(s.Trend))
+	}
+}
+
+func TestCompute_Rentals(t *testing.T) {
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	sold := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	lease := sale("2", "Danbury", "06810", sold, 3000, 2900, 2000, 10)
+	lease.ListingType = "Residential Lease"
+	listings := []models.Property{sale("1", "Danbury", "06810", sold, 500000, 500000, 2000, 10), lease}
+
+	for _, rentals := range []bool{false, true} {
+		stats := Compute(listings, Options{Months: 1, AsOf: asOf, Rentals: rentals})
+		want := 500000.0
+		if rentals {
+			want = 2900
+		}
+		if len(stats) != 1 || stats[0].Sales != 1 || stats[0].MedianSalePrice != want {
+			t.Errorf("Compute(Rentals: %v) = %+v, want one sale at %v", rentals, stats, want)
+		}
+	}
+}
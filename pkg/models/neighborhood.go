@@ -0,0 +1,58 @@
+package models
+
+import "strings"
+
+// unknownNames are the values feeds give for a school or subdivision they do
+// not know, in the form returned by normalizeName
+var unknownNames = map[string]bool{
+	"":                       true,
+	"N/A":                    true,
+	"NA":                     true,
+	"NONE":                   true,
+	"OTHER":                  true,
+	"UNKNOWN":                true,
+	"TBD":                    true,
+	"CALL":                   true,
+	"NOT APPLICABLE":         true,
+	"PER BOARD OF ED":        true,
+	"PER BOARD OF EDUCATION": true,
+	"PER BOE":                true,
+	"PER DISTRICT":           true,
+}
+
+// normalizeName upper-cases a school or subdivision name and collapses its
+// spaces and trailing periods, names that stand for an unknown value are
+// returned empty
+func normalizeName(name string) string {
+	normalized := strings.Join(strings.Fields(strings.ToUpper(name)), " ")
+	normalized = strings.TrimRight(normalized, ".")
+	if unknownNames[normalized] {
+		return ""
+	}
+	return normalized
+}
+
+// SubdivisionName returns the subdivision in standard form, empty when unknown
+func (p *Property) SubdivisionName() string {
+	return normalizeName(p.Subdivision)
+}
+
+// SchoolZones returns the schools in standard form, a school that is unknown
+// is empty
+func (p *Property) SchoolZones() Schools {
+	return Schools{
+		Elementary: normalizeName(p.Schools.Elementary),
+		Middle:     normalizeName(p.Schools.Middle),
+		High:       normalizeName(p.Schools.High),
+		District:   normalizeName(p.Schools.District),
+	}
+}
+
+// SchoolDistrict returns the school district in standard form, the high
+// school's when the listing has no district, empty when both are unknown
+func (p *Property) SchoolDistrict() string {
+	if district := normalizeName(p.Schools.District); district != "" {
+		return district
+	}
+	return normalizeName(p.Schools.High)
+}
@@ -0,0 +1,56 @@
+package models
+
+import "testing"
+
+func TestProperty_SubdivisionName(t *testing.T) {
+	tests := []struct {
+		subdivision string
+		want        string
+	}{
+		{subdivision: "Regency at Rivington", want: "REGENCY AT RIVINGTON"},
+		{subdivision: "  regency  at rivington ", want: "REGENCY AT RIVINGTON"},
+		{subdivision: "", want: ""},
+		{subdivision: "None", want: ""},
+		{subdivision: "N/A", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subdivision, func(t *testing.T) {
+			p := Property{Subdivision: tt.subdivision}
+			if got := p.SubdivisionName(); got != tt.want {
+				t.Errorf("SubdivisionName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProperty_SchoolZones(t *testing.T) {
+	p := Property{Schools: Schools{Elementary: "King Street", Middle: "Per Board of Ed.", High: "danbury"}}
+
+	want := Schools{Elementary: "KING STREET", High: "DANBURY"}
+	if got := p.SchoolZones(); got != want {
+		t.Errorf("SchoolZones() = %+v, want %+v", got, want)
+	}
+}
+
+func TestProperty_SchoolDistrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		schools Schools
+		want    string
+	}{
+		{name: "district", schools: Schools{High: "Danbury High", District: "Danbury Public Schools"}, want: "DANBURY PUBLIC SCHOOLS"},
+		{name: "high school without a district", schools: Schools{High: "Danbury"}, want: "DANBURY"},
+		{name: "unknown district", schools: Schools{High: "Danbury", District: "Per Board of Ed"}, want: "DANBURY"},
+		{name: "unknown", schools: Schools{Elementary: "King Street", High: "Per Board of Ed"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Property{Schools: tt.schools}
+			if got := p.SchoolDistrict(); got != tt.want {
+				t.Errorf("SchoolDistrict() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
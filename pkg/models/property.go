@@ -1,28 +1,73 @@
 package models
 
-import "time"
+import (
+	"math"
+	"strings"
+	"time"
+)
 
 type Property struct {
-	ID                    string   `json:"id"`
-	Address               Address  `json:"address"`
-	Baths                 Bathroom `json:"baths"`
-	Beds                  int      `json:"beds"`
-	ListPrice             float64  `json:"listPrice"`
-	SalePrice             float64  `json:"salePrice,omitempty"`
-	Size                  float64  `json:"size"`
-	Status                string   `json:"status"`
-	Style                 string   `json:"style"`
-	YearBuilt             int      `json:"yearBuilt"`
-	ListingDate           int64    `json:"listingDate"`
-	StatusChangeTimestamp int64    `json:"statusChangeTimestamp"`
-	PropertyType          string   `json:"propertyType"`
+	ID                    string      `json:"id"`
+	SystemID              string      `json:"systemId,omitempty"`
+	Address               Address     `json:"address"`
+	StdAddress            *Address    `json:"stdAddress,omitempty"`
+	County                string      `json:"county"`
+	Subdivision           string      `json:"subdivision,omitempty"`
+	Schools               Schools     `json:"schools"`
+	Coordinates           Coordinates `json:"coordinates"`
+	Baths                 Bathroom    `json:"baths"`
+	Beds                  int         `json:"beds"`
+	ListPrice             float64     `json:"listPrice"`
+	SalePrice             float64     `json:"salePrice,omitempty"`
+	PreviousListPrice     float64     `json:"previousListPrice,omitempty"`
+	PriceChangeTimestamp  int64       `json:"priceChangeTimestamp,omitempty"`
+	DaysOnMarket          int         `json:"daysOnMarket,omitempty"`
+	Size                  float64     `json:"size"`
+	Status                string      `json:"status"`
+	Style                 string      `json:"style"`
+	YearBuilt             int         `json:"yearBuilt"`
+	ListingDate           int64       `json:"listingDate"`
+	StatusChangeTimestamp int64       `json:"statusChangeTimestamp"`
+	PropertyType          string      `json:"propertyType"`
+	ListingType           string      `json:"listingType,omitempty"`
+	ModifiedDate          int64       `json:"modifiedDate,omitempty"`
+	LastUpdated           int64       `json:"lastUpdated,omitempty"`
 }
 
 type Address struct {
-	City   string `json:"city"`
-	State  string `json:"state"`
-	Zip    string `json:"zip"`
-	Street string `json:"street"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	Zip          string `json:"zip"`
+	Street       string `json:"street"`
+	DeliveryLine string `json:"deliveryLine,omitempty"`
+}
+
+// Schools are the schools whose attendance zones include the property, feeds
+// often give a placeholder such as "Per Board of Ed" instead of a name
+type Schools struct {
+	Elementary string `json:"elementary,omitempty"`
+	Middle     string `json:"middle,omitempty"`
+	High       string `json:"high,omitempty"`
+	District   string `json:"district,omitempty"`
+}
+
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// EarthRadiusMiles is the mean radius of the Earth
+const EarthRadiusMiles = 3958.8
+
+// DistanceMiles returns the great-circle distance between two points
+func DistanceMiles(a, b Coordinates) float64 {
+	toRad := math.Pi / 180
+	dLat := (b.Latitude - a.Latitude) * toRad
+	dLon := (b.Longitude - a.Longitude) * toRad
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(a.Latitude*toRad)*math.Cos(b.Latitude*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusMiles * math.Asin(math.Sqrt(h))
 }
 
 type Bathroom struct {
@@ -41,14 +86,26 @@ func (p *Property) GetPrice() float64 {
 	return p.ListPrice
 }
 
+// IsLease reports whether the listing is for rent, its prices are then
+// monthly rents
+func (p *Property) IsLease() bool {
+	listingType := strings.ToLower(p.ListingType)
+	return strings.Contains(listingType, "lease") || strings.Contains(listingType, "rental")
+}
+
 // GetAgeInMonths returns the age of the property in months
 // if the property is sold, it returns the age of the property when it was sold
 func (p *Property) GetAgeInMonths() float64 {
+	return p.AgeInMonthsAt(time.Now())
+}
+
+// AgeInMonthsAt returns the age of the property in months at the given time,
+// see GetAgeInMonths
+func (p *Property) AgeInMonthsAt(t time.Time) float64 {
 	date := p.ListingDate
 	if p.Status == "Closed" {
 		date = p.StatusChangeTimestamp
 	}
-	now := time.Now().Unix()
-	ageInMonths := float64(now-date) / (30 * 24 * 60 * 60)
+	ageInMonths := float64(t.Unix()-date) / (30 * 24 * 60 * 60)
 	return ageInMonths
 }
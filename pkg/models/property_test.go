@@ -238,3 +238,26 @@ func TestProperty_Bathroom(t *testing.T) {
 		})
 	}
 }
+
+func TestProperty_IsLease(t *testing.T) {
+	tests := []struct {
+		listingType string
+		want        bool
+	}{
+		{listingType: "Residential", want: false},
+		{listingType: "", want: false},
+		{listingType: "Residential Lease", want: true},
+		{listingType: "RESIDENTIAL LEASE", want: true},
+		{listingType: "Rental", want: true},
+		{listingType: "Commercial Lease", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.listingType, func(t *testing.T) {
+			p := Property{ListingType: tt.listingType}
+			if got := p.IsLease(); got != tt.want {
+				t.Errorf("IsLease() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package models
+
+import "fmt"
+
+// Severities of a data quality issue
+const (
+	// SeverityError marks a listing that must not be used as a comparable as is
+	SeverityError = "error"
+	// SeverityWarning marks a listing that is usable but suspicious
+	SeverityWarning = "warning"
+)
+
+// Sale to list price ratios outside this range are reported as outliers
+const (
+	minSaleToListRatio = 0.5
+	maxSaleToListRatio = 1.5
+)
+
+// Issue is a data quality problem of a listing
+type Issue struct {
+	Severity string `json:"severity"`
+	Field    string `json:"field"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s %s (%s): %s", i.Severity, i.Field, i.Rule, i.Message)
+}
+
+// Validate returns the data quality issues of the property, an empty
+// result means it can be used as a comparable
+func (p *Property) Validate() []Issue {
+	var issues []Issue
+	add := func(severity, field, rule, format string, args ...interface{}) {
+		issues = append(issues, Issue{severity, field, rule, fmt.Sprintf(format, args...)})
+	}
+
+	if p.Address.City == "" {
+		add(SeverityError, "address.city", "required", "is required")
+	}
+	if p.Size <= 0 {
+		add(SeverityError, "size", "positive", "must be greater than 0, got %v", p.Size)
+	}
+	if p.Beds < 0 {
+		add(SeverityError, "beds", "non_negative", "must not be negative, got %v", p.Beds)
+	}
+	if p.Baths.Total < 0 {
+		add(SeverityError, "baths.total", "non_negative", "must not be negative, got %v", p.Baths.Total)
+	}
+	if p.Baths.Total == 0 && (p.Baths.Full > 0 || p.Baths.Half > 0) {
+		add(SeverityWarning, "baths.total", "baths_total", "is 0 but there are %d full and %d half baths", p.Baths.Full, p.Baths.Half)
+	}
+
+	if p.ListPrice <= 0 && p.SalePrice <= 0 {
+		add(SeverityError, "listPrice", "price_required", "neither a list nor a sale price is set")
+	}
+	if p.Status == "Closed" && p.SalePrice <= 0 {
+		add(SeverityWarning, "salePrice", "sale_price_required", "is missing on a closed sale, the list price is used")
+	}
+	if p.SalePrice > 0 && p.ListPrice > 0 {
+		ratio := p.SalePrice / p.ListPrice
+		if ratio < minSaleToListRatio || ratio > maxSaleToListRatio {
+			add(SeverityWarning, "salePrice", "sale_to_list_ratio", "is %.0f%% of the list price", ratio*100)
+		}
+	}
+
+	if p.StatusChangeTimestamp > 0 && p.ListingDate > 0 && p.StatusChangeTimestamp < p.ListingDate {
+		add(SeverityError, "statusChangeTimestamp", "chronology", "is before the listing date")
+	}
+
+	return issues
+}
+
+// Repair fixes the issues that can be derived from the other fields: a
+// missing bath total is computed from the full and half baths and a status
+// change before the listing date is assumed to have the two dates swapped
+func (p *Property) Repair() {
+	if p.Baths.Total == 0 && (p.Baths.Full > 0 || p.Baths.Half > 0) {
+		p.Baths.Total = float64(p.Baths.Full) + 0.5*float64(p.Baths.Half)
+	}
+
+	if p.StatusChangeTimestamp > 0 && p.ListingDate > 0 && p.StatusChangeTimestamp < p.ListingDate {
+		p.StatusChangeTimestamp, p.ListingDate = p.ListingDate, p.StatusChangeTimestamp
+	}
+}
+
+// HasErrors reports whether any of the issues is an error
+func HasErrors(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func createValidProperty() Property {
+	return Property{
+		Address:               Address{City: "Danbury"},
+		Size:                  2000,
+		Beds:                  4,
+		Baths:                 Bathroom{Total: 2.5, Full: 2, Half: 1},
+		Status:                "Closed",
+		ListPrice:             500000,
+		SalePrice:             510000,
+		ListingDate:           1700000000,
+		StatusChangeTimestamp: 1710000000,
+	}
+}
+
+func rules(issues []Issue) []string {
+	result := []string{}
+	for _, i := range issues {
+		result = append(result, i.Severity+" "+i.Field+" "+i.Rule)
+	}
+	return result
+}
+
+func TestProperty_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(p *Property)
+		want   []string
+	}{
+		{name: "valid", modify: func(p *Property) {}, want: []string{}},
+		{name: "missing city", modify: func(p *Property) { p.Address.City = "" }, want: []string{"error address.city required"}},
+		{name: "zero size", modify: func(p *Property) { p.Size = 0 }, want: []string{"error size positive"}},
+		{name: "negative rooms", modify: func(p *Property) { p.Beds = -1; p.Baths.Total = -1 }, want: []string{"error beds non_negative", "error baths.total non_negative"}},
+		{name: "missing bath total", modify: func(p *Property) { p.Baths.Total = 0 }, want: []string{"warning baths.total baths_total"}},
+		{name: "no price", modify: func(p *Property) { p.ListPrice = 0; p.SalePrice = 0 }, want: []string{"error listPrice price_required", "warning salePrice sale_price_required"}},
+		{name: "closed without sale price", modify: func(p *Property) { p.SalePrice = 0 }, want: []string{"warning salePrice sale_price_required"}},
+		{name: "active without sale price", modify: func(p *Property) { p.SalePrice = 0; p.Status = "Active" }, want: []string{}},
+		{name: "sale far above list", modify: func(p *Property) { p.SalePrice = 800000 }, want: []string{"warning salePrice sale_to_list_ratio"}},
+		{name: "sale far below list", modify: func(p *Property) { p.SalePrice = 200000 }, want: []string{"warning salePrice sale_to_list_ratio"}},
+		{name: "status change before listing", modify: func(p *Property) { p.StatusChangeTimestamp = 1600000000 }, want: []string{"error statusChangeTimestamp chronology"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := createValidProperty()
+			tt.modify(&p)
+
+			issues := p.Validate()
+			if got := rules(issues); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+			for _, i := range issues {
+				if i.Message == "" {
+					t.Errorf("issue %v has no message", i)
+				}
+			}
+		})
+	}
+}
+
+func TestProperty_Repair(t *testing.T) {
+	p := createValidProperty()
+	p.Baths.Total = 0
+	p.StatusChangeTimestamp, p.ListingDate = p.ListingDate, p.StatusChangeTimestamp
+	p.Size = 0
+
+	p.Repair()
+
+	if p.Baths.Total != 2.5 {
+		t.Errorf("Repair() baths total = %v, want 2.5", p.Baths.Total)
+	}
+	if p.ListingDate != 1700000000 || p.StatusChangeTimestamp != 1710000000 {
+		t.Errorf("Repair() dates = %v, %v, want them swapped back", p.ListingDate, p.StatusChangeTimestamp)
+	}
+	if got := rules(p.Validate()); !reflect.DeepEqual(got, []string{"error size positive"}) {
+		t.Errorf("Validate() after Repair() = %v, want only the size error", got)
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	warning := Issue{Severity: SeverityWarning}
+	err := Issue{Severity: SeverityError}
+
+	if HasErrors(nil) || HasErrors([]Issue{warning}) {
+		t.Error("HasErrors() = true without errors")
+	}
+	if !HasErrors([]Issue{warning, err}) {
+		t.Error("HasErrors() = false with an error")
+	}
+}
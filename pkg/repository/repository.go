@@ -0,0 +1,393 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+// Change is the status and price of a listing at one point in time
+type Change struct {
+	Timestamp int64   `json:"timestamp"`
+	Status    string  `json:"status"`
+	ListPrice float64 `json:"listPrice"`
+	SalePrice float64 `json:"salePrice,omitempty"`
+}
+
+// Record is the latest version of a listing and the history of its status and price
+type Record struct {
+	Key     string          `json:"key"`
+	Listing models.Property `json:"listing"`
+	History []Change        `json:"history"`
+}
+
+// IngestStats counts what an ingestion did with each listing
+type IngestStats struct {
+	Inserted  int `json:"inserted"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Invalid   int `json:"invalid"`
+}
+
+// Repository is a persistent listing store in an embedded bbolt key/value
+// file. Records are stored by listing key and indexed on disk by city and
+// size, so a query for a city and size range only reads the listings in it.
+// It is safe for concurrent use.
+type Repository struct {
+	path string
+
+	mu sync.RWMutex
+	db *bolt.DB
+}
+
+var (
+	// listingsBucket maps a listing key to its JSON record
+	listingsBucket = []byte("listings")
+	// cityBucket holds one empty entry per listing keyed by its city
+	// without designation, its size and its listing key, see indexKey
+	cityBucket = []byte("listings_by_city_base_size")
+	// oldCityBuckets are the city indexes of earlier versions, they are
+	// dropped and the index is built again when a repository is opened
+	oldCityBuckets = [][]byte{[]byte("listings_by_city_size")}
+)
+
+// openTimeout is how long Open waits for another process holding the file
+const openTimeout = time.Second
+
+// Open opens the repository file, creating it if it does not exist
+func Open(path string) (*Repository, error) {
+	db, err := openDB(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Repository{path: path, db: db}, nil
+}
+
+func openDB(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %v", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range oldCityBuckets {
+			if err := tx.DeleteBucket(name); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+				return err
+			}
+		}
+		if tx.Bucket(cityBucket) != nil {
+			_, err := tx.CreateBucketIfNotExists(listingsBucket)
+			return err
+		}
+
+		records, err := tx.CreateBucketIfNotExists(listingsBucket)
+		if err != nil {
+			return err
+		}
+		index, err := tx.CreateBucket(cityBucket)
+		if err != nil {
+			return err
+		}
+		return records.ForEach(func(key, data []byte) error {
+			var record Record
+			if err := json.Unmarshal(data, &record); err != nil {
+				return fmt.Errorf("corrupt repository record %s: %v", key, err)
+			}
+			return index.Put(indexKey(record.Listing, string(key)), nil)
+		})
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open repository: %v", err)
+	}
+	return db, nil
+}
+
+// indexKey returns the key of a listing in the city index: the city, a zero
+// byte, the size in a byte order that sorts like the number and the listing
+// key, so that the listings of a city within a size range are contiguous
+func indexKey(p models.Property, key string) []byte {
+	k := cityPrefix(p.Address.City)
+	k = binary.BigEndian.AppendUint64(k, sortableSize(p.Size))
+	return append(k, key...)
+}
+
+func cityPrefix(city string) []byte {
+	return append([]byte(address.CityBase(city)), 0)
+}
+
+// sortableSize maps a size to an integer of the same order, the sign bit of
+// positive numbers is set and every bit of negative ones flipped
+func sortableSize(size float64) uint64 {
+	bits := math.Float64bits(size)
+	if size < 0 {
+		return ^bits
+	}
+	return bits | 1<<63
+}
+
+// Key returns the key a listing is stored under, the feed's system id when
+// present and the listing id otherwise
+func Key(p models.Property) string {
+	if p.SystemID != "" {
+		return p.SystemID
+	}
+	return p.ID
+}
+
+// Ingest upserts the listings in one transaction. A listing replaces the
+// stored version only if its lastUpdated or modifiedDate is newer, listings
+// without either are compared by content. A change of status or price is
+// added to the history.
+func (r *Repository) Ingest(listings []models.Property) (IngestStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var stats IngestStats
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		records, index := tx.Bucket(listingsBucket), tx.Bucket(cityBucket)
+
+		for _, listing := range listings {
+			key := Key(listing)
+			if key == "" {
+				stats.Invalid++
+				continue
+			}
+
+			stored, err := getRecord(records, key)
+			if err != nil {
+				return err
+			}
+			if stored != nil && !changed(stored.Listing, listing) {
+				stats.Unchanged++
+				continue
+			}
+
+			record := Record{Key: key, Listing: listing}
+			if stored != nil {
+				record.History = append(record.History, stored.History...)
+				if err := index.Delete(indexKey(stored.Listing, key)); err != nil {
+					return err
+				}
+				stats.Updated++
+			} else {
+				stats.Inserted++
+			}
+			record.History = appendChange(record.History, listing)
+
+			data, err := json.Marshal(record)
+			if err != nil {
+				return fmt.Errorf("failed to encode listing %s: %v", key, err)
+			}
+			if err := records.Put([]byte(key), data); err != nil {
+				return err
+			}
+			if err := index.Put(indexKey(listing, key), nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return IngestStats{}, fmt.Errorf("failed to write repository: %v", err)
+	}
+	return stats, nil
+}
+
+// getRecord decodes the record of a key, nil when there is none
+func getRecord(records *bolt.Bucket, key string) (*Record, error) {
+	data := records.Get([]byte(key))
+	if data == nil {
+		return nil, nil
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("corrupt repository record %s: %v", key, err)
+	}
+	return &record, nil
+}
+
+// changed reports whether an incoming listing is a newer version of the stored one
+func changed(stored, incoming models.Property) bool {
+	if incoming.LastUpdated != 0 || incoming.ModifiedDate != 0 {
+		if incoming.LastUpdated != stored.LastUpdated {
+			return incoming.LastUpdated > stored.LastUpdated
+		}
+		return incoming.ModifiedDate > stored.ModifiedDate
+	}
+	return !reflect.DeepEqual(stored, incoming)
+}
+
+func appendChange(history []Change, p models.Property) []Change {
+	change := Change{
+		Timestamp: changeTime(p),
+		Status:    p.Status,
+		ListPrice: p.ListPrice,
+		SalePrice: p.SalePrice,
+	}
+
+	if n := len(history); n > 0 {
+		last := history[n-1]
+		if last.Status == change.Status && last.ListPrice == change.ListPrice && last.SalePrice == change.SalePrice {
+			return history
+		}
+	}
+	return append(history, change)
+}
+
+// changeTime returns when the feed last changed the listing, the time of
+// ingestion if the listing carries no timestamp
+func changeTime(p models.Property) int64 {
+	for _, t := range []int64{p.LastUpdated, p.ModifiedDate, p.StatusChangeTimestamp, p.ListingDate} {
+		if t != 0 {
+			return t
+		}
+	}
+	return time.Now().Unix()
+}
+
+// Get returns the stored record of a listing key
+func (r *Repository) Get(key string) (Record, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var record *Record
+	r.db.View(func(tx *bolt.Tx) error {
+		record, _ = getRecord(tx.Bucket(listingsBucket), key)
+		return nil
+	})
+	if record == nil {
+		return Record{}, false
+	}
+	return *record, true
+}
+
+// Len returns the number of listings in the repository
+func (r *Repository) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := 0
+	r.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(listingsBucket).Stats().KeyN
+		return nil
+	})
+	return n
+}
+
+// Listings returns the latest version of the listings matching the query in
+// key order. A query for a city reads the city index from the lower size
+// bound up to the upper one, other queries read every listing.
+func (r *Repository) Listings(q store.Query) ([]models.Property, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []Record
+	err := r.db.View(func(tx *bolt.Tx) error {
+		records := tx.Bucket(listingsBucket)
+		add := func(key []byte, data []byte) error {
+			var record Record
+			if err := json.Unmarshal(data, &record); err != nil {
+				return fmt.Errorf("corrupt repository record %s: %v", key, err)
+			}
+			if q.Matches(record.Listing) {
+				matches = append(matches, record)
+			}
+			return nil
+		}
+
+		if q.City == "" {
+			return records.ForEach(add)
+		}
+
+		prefix := cityPrefix(q.City)
+		start := prefix
+		if q.MinSize > 0 {
+			start = binary.BigEndian.AppendUint64(append([]byte(nil), prefix...), sortableSize(q.MinSize))
+		}
+		cursor := tx.Bucket(cityBucket).Cursor()
+		for k, _ := cursor.Seek(start); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+			rest := k[len(prefix):]
+			if q.MaxSize > 0 && binary.BigEndian.Uint64(rest) > sortableSize(q.MaxSize) {
+				break
+			}
+			key := rest[8:]
+			if err := add(key, records.Get(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Key < matches[j].Key })
+	listings := make([]models.Property, len(matches))
+	for i, record := range matches {
+		listings[i] = record.Listing
+	}
+	return listings, nil
+}
+
+// Compact copies the listings to a new file without the free pages left by
+// updated records and replaces the repository file with it
+func (r *Repository) Compact() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to compact repository: %v", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	compacted, err := bolt.Open(tmp.Name(), 0o644, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return fmt.Errorf("failed to compact repository: %v", err)
+	}
+	err = bolt.Compact(compacted, r.db, 0)
+	if err = errors.Join(err, compacted.Close()); err != nil {
+		return fmt.Errorf("failed to compact repository: %v", err)
+	}
+
+	if err := r.db.Close(); err != nil {
+		return fmt.Errorf("failed to compact repository: %v", err)
+	}
+	renameErr := os.Rename(tmp.Name(), r.path)
+
+	// the repository is reopened even if the file could not be replaced
+	db, err := openDB(r.path)
+	if err != nil {
+		return err
+	}
+	r.db = db
+	if renameErr != nil {
+		return fmt.Errorf("failed to compact repository: %v", renameErr)
+	}
+	return nil
+}
+
+// Close closes the repository file
+func (r *Repository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.db.Close()
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+func createTestListing(systemID, status string, listPrice float64, lastUpdated int64) models.Property {
+	return models.Property{
+		ID:          "mls-" + systemID,
+		SystemID:    systemID,
+		Address:     models.Address{City: "Danbury", State: "CT"},
+		Size:        2000,
+		Status:      status,
+		ListPrice:   listPrice,
+		LastUpdated: lastUpdated,
+	}
+}
+
+func openTestRepository(t *testing.T, path string) *Repository {
+	t.Helper()
+	repo, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestRepository_Ingest(t *testing.T) {
+	repo := openTestRepository(t, filepath.Join(t.TempDir(), "listings.db"))
+
+	stats, err := repo.Ingest([]models.Property{
+		createTestListing("1", "Active", 500000, 100),
+		createTestListing("2", "Active", 600000, 100),
+		{Address: models.Address{City: "Danbury"}},
+	})
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if want := (IngestStats{Inserted: 2, Invalid: 1}); stats != want {
+		t.Errorf("first Ingest() = %+v, want %+v", stats, want)
+	}
+
+	stats, err = repo.Ingest([]models.Property{
+		createTestListing("1", "Active", 500000, 100),
+		createTestListing("2", "Active", 550000, 50),
+		createTestListing("1", "Active", 480000, 200),
+		createTestListing("1", "Closed", 480000, 300),
+		{ID: "3", Address: models.Address{City: "Danbury"}, Status: "Active", ListPrice: 300000},
+	})
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if want := (IngestStats{Inserted: 1, Updated: 2, Unchanged: 2}); stats != want {
+		t.Errorf("second Ingest() = %+v, want %+v", stats, want)
+	}
+
+	record, ok := repo.Get("1")
+	if !ok {
+		t.Fatal("Get(1) found no record")
+	}
+	if record.Listing.Status != "Closed" || record.Listing.LastUpdated != 300 {
+		t.Errorf("Get(1) listing = %+v, want the closed version", record.Listing)
+	}
+	wantHistory := []Change{
+		{Timestamp: 100, Status: "Active", ListPrice: 500000},
+		{Timestamp: 200, Status: "Active", ListPrice: 480000},
+		{Timestamp: 300, Status: "Closed", ListPrice: 480000},
+	}
+	if !reflect.DeepEqual(record.History, wantHistory) {
+		t.Errorf("Get(1) history = %+v, want %+v", record.History, wantHistory)
+	}
+
+	if record, _ := repo.Get("2"); record.Listing.ListPrice != 600000 {
+		t.Errorf("an older version replaced listing 2: %+v", record.Listing)
+	}
+
+	// listings without timestamps are compared by content
+	stats, _ = repo.Ingest([]models.Property{{ID: "3", Address: models.Address{City: "Danbury"}, Status: "Active", ListPrice: 300000}})
+	if stats.Unchanged != 1 {
+		t.Errorf("re-ingesting an identical listing = %+v, want it unchanged", stats)
+	}
+}
+
+func TestRepository_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "listings.db")
+
+	repo := openTestRepository(t, path)
+	repo.Ingest([]models.Property{createTestListing("1", "Active", 500000, 100)})
+	repo.Ingest([]models.Property{createTestListing("1", "Pending", 500000, 200)})
+	repo.Ingest([]models.Property{createTestListing("2", "Active", 700000, 100)})
+	repo.Close()
+
+	repo = openTestRepository(t, path)
+	if repo.Len() != 2 {
+		t.Fatalf("Len() after reopening = %d, want 2", repo.Len())
+	}
+	if record, _ := repo.Get("1"); record.Listing.Status != "Pending" || len(record.History) != 2 {
+		t.Errorf("Get(1) after reopening = %+v, want the pending version with 2 changes", record)
+	}
+
+	if err := repo.Compact(); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	repo.Ingest([]models.Property{createTestListing("3", "Active", 400000, 100)})
+	if listings, _ := repo.Listings(store.Query{City: "Danbury"}); len(listings) != 3 {
+		t.Errorf("Listings(Danbury) after compaction = %d listings, want 3", len(listings))
+	}
+	repo.Close()
+
+	repo = openTestRepository(t, path)
+	if repo.Len() != 3 {
+		t.Errorf("Len() after compaction = %d, want 3", repo.Len())
+	}
+	if record, _ := repo.Get("1"); len(record.History) != 2 {
+		t.Errorf("Get(1) after compaction has %d changes, want 2", len(record.History))
+	}
+}
+
+func TestRepository_Listings(t *testing.T) {
+	repo := openTestRepository(t, filepath.Join(t.TempDir(), "listings.db"))
+
+	norwalk := createTestListing("3", "Active", 500000, 100)
+	norwalk.Address.City = "Norwalk"
+	repo.Ingest([]models.Property{
+		createTestListing("1", "Active", 500000, 100),
+		createTestListing("2", "Closed", 600000, 100),
+		norwalk,
+	})
+
+	listings, err := repo.Listings(store.Query{City: "Danbury"})
+	if err != nil {
+		t.Fatalf("Listings() error = %v", err)
+	}
+	if len(listings) != 2 {
+		t.Errorf("Listings(Danbury) returned %d listings, want 2", len(listings))
+	}
+
+	// the index is rebuilt after an ingestion
+	repo.Ingest([]models.Property{createTestListing("2", "Closed", 600000, 200)})
+	repo.Ingest([]models.Property{createTestListing("4", "Active", 500000, 100)})
+	listings, _ = repo.Listings(store.Query{City: "Danbury", Statuses: []string{"Active"}})
+	if len(listings) != 2 {
+		t.Errorf("Listings(Danbury, Active) returned %d listings, want 2", len(listings))
+	}
+}
+
+func TestRepository_ListingsOfDesignatedCity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "listings.db")
+
+	town := createTestListing("1", "Active", 500000, 100)
+	town.Address.City = "Danbury Town"
+	repo := openTestRepository(t, path)
+	repo.Ingest([]models.Property{town, createTestListing("2", "Active", 600000, 100)})
+	repo.Close()
+
+	// the index of an earlier version is built again when the file is opened
+	db, err := bolt.Open(path, 0o644, nil)
+	if err != nil {
+		t.Fatalf("bolt.Open() error = %v", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(cityBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(oldCityBuckets[0])
+		return err
+	})
+	db.Close()
+	if err != nil {
+		t.Fatalf("replacing the city index: %v", err)
+	}
+
+	// the listings were indexed before the city was designated
+	address.SetDesignatedCities([]string{"Danbury"})
+	t.Cleanup(func() { address.SetDesignatedCities(nil) })
+
+	repo = openTestRepository(t, path)
+	listings, err := repo.Listings(store.Query{City: "Danbury"})
+	if err != nil {
+		t.Fatalf("Listings() error = %v", err)
+	}
+	if len(listings) != 2 {
+		t.Errorf("Listings(Danbury) returned %d listings, want 2", len(listings))
+	}
+}
+
+func TestRepository_ListingsMatchesQuery(t *testing.T) {
+	repo := openTestRepository(t, filepath.Join(t.TempDir(), "listings.db"))
+
+	rng := rand.New(rand.NewSource(1))
+	cities := []string{"Danbury", "Norwalk", "Stamford", "danbury"}
+	listings := make([]models.Property, 500)
+	for i := range listings {
+		listings[i] = models.Property{
+			ID:      fmt.Sprintf("%03d", i),
+			Address: models.Address{City: cities[rng.Intn(len(cities))]},
+			Size:    float64(rng.Intn(4000)),
+			Status:  "Active",
+		}
+	}
+	// a size of zero and a negative one sort before every other size
+	listings[0].Size, listings[1].Size = 0, -1
+	if _, err := repo.Ingest(listings); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+
+	// resized listings leave the old position in the index
+	for i := 0; i < len(listings); i += 7 {
+		listings[i].Size += 1500
+		listings[i].ListPrice = 1
+	}
+	if _, err := repo.Ingest(listings); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+
+	queries := []store.Query{
+		{},
+		{City: "Danbury"},
+		{City: "DANBURY"},
+		{City: "Norwalk", MinSize: 2000},
+		{City: "Stamford", MaxSize: 1500},
+		{City: "Danbury", MinSize: 1000, MaxSize: 2500},
+		{City: "Danbury", MinSize: 2500, MaxSize: 2500},
+		{City: "Hartford"},
+		{MinSize: 3000},
+	}
+	for _, q := range queries {
+		got, err := repo.Listings(q)
+		if err != nil {
+			t.Fatalf("Listings(%+v) error = %v", q, err)
+		}
+		want := store.New(listings).Query(q)
+		sort.Slice(want, func(i, j int) bool { return want[i].ID < want[j].ID })
+
+		if len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+			t.Errorf("Listings(%+v) returned %d listings, want the %d of an in-memory store", q, len(got), len(want))
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package reso
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+// Client reads Property resources from a RESO Web API (OData) endpoint
+type Client struct {
+	// BaseURL is the service root, the resource is appended to it
+	BaseURL  string
+	Resource string
+	Token    string
+	Mapping  Mapping
+
+	HTTP *http.Client
+	// PageSize is the $top of every request
+	PageSize int
+}
+
+// NewClient creates a client for the Property resource with the default mapping
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		BaseURL:  strings.TrimRight(baseURL, "/"),
+		Resource: "Property",
+		Token:    token,
+		Mapping:  DefaultMapping(),
+		HTTP:     &http.Client{Timeout: 30 * time.Second},
+		PageSize: 200,
+	}
+}
+
+// page is an OData collection response
+type page struct {
+	Value    []map[string]interface{} `json:"value"`
+	NextLink string                   `json:"@odata.nextLink"`
+}
+
+// Fetch returns the resources matching an OData $filter expression, an
+// empty filter selects every resource. Pages are requested with $top and
+// $skip unless the server returns an @odata.nextLink.
+func (c *Client) Fetch(ctx context.Context, filter string) ([]models.Property, error) {
+	values := url.Values{}
+	if filter != "" {
+		values.Set("$filter", filter)
+	}
+	values.Set("$top", strconv.Itoa(c.PageSize))
+
+	var listings []models.Property
+	next := ""
+	for skip := 0; ; {
+		endpoint := next
+		if endpoint == "" {
+			values.Set("$skip", strconv.Itoa(skip))
+			endpoint = c.BaseURL + "/" + c.Resource + "?" + values.Encode()
+		}
+
+		page, err := c.get(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, record := range page.Value {
+			p, err := c.Mapping.Property(record)
+			if err != nil {
+				return nil, fmt.Errorf("resource %d: %v", len(listings)+i+1, err)
+			}
+			listings = append(listings, p)
+		}
+
+		switch {
+		case page.NextLink != "":
+			next = page.NextLink
+		case next == "" && len(page.Value) == c.PageSize:
+			skip += c.PageSize
+		default:
+			return listings, nil
+		}
+	}
+}
+
+// Listings queries the resources matching the query, it implements source.ListingSource
+func (c *Client) Listings(q store.Query) ([]models.Property, error) {
+	listings, err := c.Fetch(context.Background(), c.Filter(q))
+	if err != nil {
+		return nil, err
+	}
+
+	// the radius is not part of the filter and the city is matched loosely
+	result := listings[:0]
+	for _, p := range listings {
+		if q.Matches(p) {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// Filter returns the OData $filter expression of a query using the mapped
+// RESO field names, conditions on unmapped fields are left out. The city is
+// matched on the start of its lower-cased spellings, so that the listings of
+// "Danbury Town" or "DANBURY" are returned for Danbury, and Listings keeps
+// those of the same city.
+func (c *Client) Filter(q store.Query) string {
+	fields := c.Mapping.Fields
+	var conditions []string
+	add := func(field, format string, args ...interface{}) {
+		if name, ok := fields[field]; ok {
+			conditions = append(conditions, name+" "+fmt.Sprintf(format, args...))
+		}
+	}
+
+	if name, ok := fields["address.city"]; ok && q.City != "" {
+		var spellings []string
+		for _, spelling := range address.CitySpellings(q.City) {
+			spellings = append(spellings, "startswith(tolower("+name+"), "+quote(strings.ToLower(spelling))+")")
+		}
+		switch len(spellings) {
+		case 0:
+		case 1:
+			conditions = append(conditions, spellings[0])
+		default:
+			conditions = append(conditions, "("+strings.Join(spellings, " or ")+")")
+		}
+	}
+	if q.Zip != "" {
+		add("address.zip", "eq %s", quote(q.Zip))
+	}
+	if q.MinSize > 0 {
+		add("size", "ge %g", q.MinSize)
+	}
+	if q.MaxSize > 0 {
+		add("size", "le %g", q.MaxSize)
+	}
+	if q.SoldAfter > 0 {
+		add("statusChangeTimestamp", "ge %s", date(q.SoldAfter))
+	}
+	if q.SoldBefore > 0 {
+		add("statusChangeTimestamp", "le %s", date(q.SoldBefore))
+	}
+
+	if name, ok := fields["status"]; ok && len(q.Statuses) > 0 {
+		var statuses []string
+		for _, status := range q.Statuses {
+			for _, reso := range c.Mapping.resoStatuses(status) {
+				statuses = append(statuses, name+" eq "+quote(reso))
+			}
+		}
+		conditions = append(conditions, "("+strings.Join(statuses, " or ")+")")
+	}
+
+	return strings.Join(conditions, " and ")
+}
+
+func (c *Client) get(ctx context.Context, endpoint string) (*page, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("RESO request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("RESO request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RESO request failed: %s", resp.Status)
+	}
+
+	var p page
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid RESO response: %v", err)
+	}
+	return &p, nil
+}
+
+// quote returns an OData string literal
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// date returns the OData date literal of a Unix timestamp in UTC
+func date(timestamp int64) string {
+	return time.Unix(timestamp, 0).UTC().Format("2006-01-02")
+}
@@ -0,0 +1,203 @@
+package reso
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+// stubServer serves numbered Property resources in $top/$skip pages and
+// records the $filter of every request. The resources are in Danbury,
+// written as "DANBURY TOWN" every third one, or in Danburyport.
+type stubServer struct {
+	*httptest.Server
+	total int
+
+	mu      sync.Mutex
+	filters []string
+	skips   []string
+}
+
+func newStubServer(t *testing.T, total int, nextLink bool) *stubServer {
+	s := &stubServer{total: total}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/odata/Property" || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+
+		query := r.URL.Query()
+		s.mu.Lock()
+		s.filters = append(s.filters, query.Get("$filter"))
+		s.skips = append(s.skips, query.Get("$skip"))
+		s.mu.Unlock()
+
+		top, _ := strconv.Atoi(query.Get("$top"))
+		skip, _ := strconv.Atoi(query.Get("$skip"))
+
+		var resp struct {
+			Value    []map[string]interface{} `json:"value"`
+			NextLink string                   `json:"@odata.nextLink,omitempty"`
+		}
+		resp.Value = []map[string]interface{}{}
+		for i := skip; i < skip+top && i < s.total; i++ {
+			status := "Closed"
+			if i%2 == 1 {
+				status = "Pending"
+			}
+			city := []string{"Danbury", "DANBURY TOWN", "Danburyport"}[i%3]
+			resp.Value = append(resp.Value, map[string]interface{}{
+				"ListingKey":     fmt.Sprint(i),
+				"City":           city,
+				"LivingArea":     2000 + i,
+				"StandardStatus": status,
+				"ClosePrice":     500000,
+			})
+		}
+		if nextLink && skip+top < s.total {
+			next := *r.URL
+			query.Set("$skip", strconv.Itoa(skip+top))
+			next.RawQuery = query.Encode()
+			resp.NextLink = s.URL + next.String()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestClient_Fetch(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int
+		nextLink  bool
+		wantSkips []string
+	}{
+		{name: "skip paging", total: 7, wantSkips: []string{"0", "3", "6"}},
+		{name: "exact last page", total: 6, wantSkips: []string{"0", "3", "6"}},
+		{name: "next links", total: 7, nextLink: true, wantSkips: []string{"0", "3", "6"}},
+		{name: "empty", total: 0, wantSkips: []string{"0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newStubServer(t, tt.total, tt.nextLink)
+			client := NewClient(server.URL+"/odata/", "token")
+			client.PageSize = 3
+
+			listings, err := client.Fetch(context.Background(), "City eq 'Danbury'")
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+
+			if len(listings) != tt.total {
+				t.Fatalf("Fetch() returned %d listings, want %d", len(listings), tt.total)
+			}
+			for i, l := range listings {
+				if l.ID != fmt.Sprint(i) {
+					t.Errorf("listing %d has id %s", i, l.ID)
+				}
+			}
+			if fmt.Sprint(server.skips) != fmt.Sprint(tt.wantSkips) {
+				t.Errorf("requested $skip %v, want %v", server.skips, tt.wantSkips)
+			}
+			for _, f := range server.filters {
+				if f != "City eq 'Danbury'" {
+					t.Errorf("requested $filter %q, want City eq 'Danbury'", f)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_FetchErrors(t *testing.T) {
+	server := newStubServer(t, 3, false)
+
+	unauthorized := NewClient(server.URL+"/odata", "wrong")
+	if _, err := unauthorized.Fetch(context.Background(), ""); err == nil {
+		t.Error("Fetch() with an invalid token expected an error")
+	}
+
+	invalid := NewClient(server.URL+"/odata", "token")
+	invalid.Mapping.Fields["statusChangeTimestamp"] = "City"
+	if _, err := invalid.Fetch(context.Background(), ""); err == nil {
+		t.Error("Fetch() with a value that cannot be mapped expected an error")
+	}
+}
+
+func TestClient_Filter(t *testing.T) {
+	client := NewClient("http://localhost", "")
+
+	tests := []struct {
+		name  string
+		query store.Query
+		want  string
+	}{
+		{name: "empty", query: store.Query{}, want: ""},
+		{name: "city with quote", query: store.Query{City: "Coeur d'Alene"}, want: "startswith(tolower(City), 'coeur d''alene')"},
+		{name: "designated city", query: store.Query{City: "Danbury Town"}, want: "startswith(tolower(City), 'danbury')"},
+		{
+			name:  "abbreviated city",
+			query: store.Query{City: "St. Louis"},
+			want:  "(startswith(tolower(City), 'st. louis') or startswith(tolower(City), 'saint louis') or startswith(tolower(City), 'st louis'))",
+		},
+		{
+			name:  "comparable query",
+			query: store.Query{City: "Danbury", Zip: "06810", MinSize: 2200, MaxSize: 3300},
+			want:  "startswith(tolower(City), 'danbury') and PostalCode eq '06810' and LivingArea ge 2200 and LivingArea le 3300",
+		},
+		{
+			name:  "statuses and sale dates",
+			query: store.Query{Statuses: []string{"Under Contract", "Sold"}, SoldAfter: 1704067200, SoldBefore: 1735689599},
+			want:  "CloseDate ge 2024-01-01 and CloseDate le 2024-12-31 and (StandardStatus eq 'Active Under Contract' or StandardStatus eq 'Pending' or StandardStatus eq 'Sold')",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := client.Filter(tt.query); got != tt.want {
+				t.Errorf("Filter() = %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Listings(t *testing.T) {
+	address.SetDesignatedCities([]string{"Danbury"})
+	t.Cleanup(func() { address.SetDesignatedCities(nil) })
+
+	server := newStubServer(t, 10, false)
+	client := NewClient(server.URL+"/odata", "token")
+
+	listings, err := client.Listings(store.Query{City: "Danbury", Statuses: []string{"Under Contract"}})
+	if err != nil {
+		t.Fatalf("Listings() error = %v", err)
+	}
+
+	// the stub ignores the filter, the client applies the query to what it
+	// returns: the pending listings of Danbury under either name
+	if len(listings) != 4 {
+		t.Errorf("Listings() returned %d listings, want 4", len(listings))
+	}
+	for _, l := range listings {
+		if l.Status != "Under Contract" {
+			t.Errorf("listing %s has status %q, want Under Contract", l.ID, l.Status)
+		}
+		if l.Address.City == "Danburyport" {
+			t.Errorf("listing %s is in %s, want Danbury", l.ID, l.Address.City)
+		}
+	}
+	if want := "startswith(tolower(City), 'danbury') and (StandardStatus eq 'Active Under Contract' or StandardStatus eq 'Pending')"; server.filters[0] != want {
+		t.Errorf("requested $filter %q, want %q", server.filters[0], want)
+	}
+}
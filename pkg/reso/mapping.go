@@ -0,0 +1,249 @@
+package reso
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+// Mapping describes how RESO Property resources map to models.Property.
+// Fields maps a property field, named by its JSON path such as
+// "address.city", to the RESO field it is read from. Statuses maps RESO
+// StandardStatus values to the statuses the valuation understands, other
+// values are kept unchanged.
+type Mapping struct {
+	Fields   map[string]string `json:"fields"`
+	Statuses map[string]string `json:"statuses"`
+}
+
+// DefaultMapping returns the mapping of the RESO Data Dictionary fields
+func DefaultMapping() Mapping {
+	return Mapping{
+		Fields: map[string]string{
+			"id":                    "ListingKey",
+			"address.street":        "UnparsedAddress",
+			"address.city":          "City",
+			"address.state":         "StateOrProvince",
+			"address.zip":           "PostalCode",
+			"county":                "CountyOrParish",
+			"subdivision":           "SubdivisionName",
+			"schools.elementary":    "ElementarySchool",
+			"schools.middle":        "MiddleOrJuniorSchool",
+			"schools.high":          "HighSchool",
+			"schools.district":      "HighSchoolDistrict",
+			"coordinates.latitude":  "Latitude",
+			"coordinates.longitude": "Longitude",
+			"baths.total":           "BathroomsTotalInteger",
+			"baths.full":            "BathroomsFull",
+			"baths.half":            "BathroomsHalf",
+			"beds":                  "BedroomsTotal",
+			"listPrice":             "ListPrice",
+			"salePrice":             "ClosePrice",
+			"previousListPrice":     "PreviousListPrice",
+			"priceChangeTimestamp":  "PriceChangeTimestamp",
+			"daysOnMarket":          "DaysOnMarket",
+			"size":                  "LivingArea",
+			"status":                "StandardStatus",
+			"style":                 "ArchitecturalStyle",
+			"yearBuilt":             "YearBuilt",
+			"listingDate":           "ListingContractDate",
+			"statusChangeTimestamp": "CloseDate",
+			"propertyType":          "PropertySubType",
+			"listingType":           "PropertyType",
+			"modifiedDate":          "ModificationTimestamp",
+		},
+		Statuses: map[string]string{
+			"Active":                "Active",
+			"Active Under Contract": "Under Contract",
+			"Pending":               "Under Contract",
+			"Closed":                "Closed",
+		},
+	}
+}
+
+// LoadMapping reads a mapping file and applies it over the default mapping,
+// a field mapped to an empty string is not read
+func LoadMapping(path string) (Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Mapping{}, err
+	}
+
+	var overrides Mapping
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&overrides); err != nil {
+		return Mapping{}, fmt.Errorf("error parsing RESO mapping: %v", err)
+	}
+
+	mapping := DefaultMapping()
+	for field, resoField := range overrides.Fields {
+		if _, ok := setters[field]; !ok {
+			return Mapping{}, fmt.Errorf("error parsing RESO mapping: unknown property field %q", field)
+		}
+		if resoField == "" {
+			delete(mapping.Fields, field)
+			continue
+		}
+		mapping.Fields[field] = resoField
+	}
+	for status, mapped := range overrides.Statuses {
+		mapping.Statuses[status] = mapped
+	}
+
+	return mapping, nil
+}
+
+// Property maps a decoded RESO Property resource
+func (m Mapping) Property(record map[string]interface{}) (models.Property, error) {
+	var p models.Property
+
+	fields := make([]string, 0, len(m.Fields))
+	for field := range m.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		resoField := m.Fields[field]
+		value, ok := record[resoField]
+		if !ok || value == nil {
+			continue
+		}
+		if err := setters[field](&p, value); err != nil {
+			return models.Property{}, fmt.Errorf("%s: %v", resoField, err)
+		}
+	}
+
+	if status, ok := m.Statuses[p.Status]; ok {
+		p.Status = status
+	}
+	return p, nil
+}
+
+// resoStatuses returns the RESO statuses mapped to a status
+func (m Mapping) resoStatuses(status string) []string {
+	var result []string
+	for reso, mapped := range m.Statuses {
+		if mapped == status {
+			result = append(result, reso)
+		}
+	}
+	if len(result) == 0 {
+		result = append(result, status)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// setters maps the property fields to the function converting and setting a RESO value
+var setters = map[string]func(p *models.Property, v interface{}) error{
+	"id":                    func(p *models.Property, v interface{}) error { return setString(&p.ID, v) },
+	"systemId":              func(p *models.Property, v interface{}) error { return setString(&p.SystemID, v) },
+	"address.street":        func(p *models.Property, v interface{}) error { return setString(&p.Address.Street, v) },
+	"address.city":          func(p *models.Property, v interface{}) error { return setString(&p.Address.City, v) },
+	"address.state":         func(p *models.Property, v interface{}) error { return setString(&p.Address.State, v) },
+	"address.zip":           func(p *models.Property, v interface{}) error { return setString(&p.Address.Zip, v) },
+	"county":                func(p *models.Property, v interface{}) error { return setString(&p.County, v) },
+	"subdivision":           func(p *models.Property, v interface{}) error { return setString(&p.Subdivision, v) },
+	"schools.elementary":    func(p *models.Property, v interface{}) error { return setString(&p.Schools.Elementary, v) },
+	"schools.middle":        func(p *models.Property, v interface{}) error { return setString(&p.Schools.Middle, v) },
+	"schools.high":          func(p *models.Property, v interface{}) error { return setString(&p.Schools.High, v) },
+	"schools.district":      func(p *models.Property, v interface{}) error { return setString(&p.Schools.District, v) },
+	"coordinates.latitude":  func(p *models.Property, v interface{}) error { return setFloat(&p.Coordinates.Latitude, v) },
+	"coordinates.longitude": func(p *models.Property, v interface{}) error { return setFloat(&p.Coordinates.Longitude, v) },
+	"baths.total":           func(p *models.Property, v interface{}) error { return setFloat(&p.Baths.Total, v) },
+	"baths.full":            func(p *models.Property, v interface{}) error { return setInt(&p.Baths.Full, v) },
+	"baths.half":            func(p *models.Property, v interface{}) error { return setInt(&p.Baths.Half, v) },
+	"beds":                  func(p *models.Property, v interface{}) error { return setInt(&p.Beds, v) },
+	"listPrice":             func(p *models.Property, v interface{}) error { return setFloat(&p.ListPrice, v) },
+	"salePrice":             func(p *models.Property, v interface{}) error { return setFloat(&p.SalePrice, v) },
+	"previousListPrice":     func(p *models.Property, v interface{}) error { return setFloat(&p.PreviousListPrice, v) },
+	"priceChangeTimestamp":  func(p *models.Property, v interface{}) error { return setTime(&p.PriceChangeTimestamp, v) },
+	"daysOnMarket":          func(p *models.Property, v interface{}) error { return setInt(&p.DaysOnMarket, v) },
+	"size":                  func(p *models.Property, v interface{}) error { return setFloat(&p.Size, v) },
+	"status":                func(p *models.Property, v interface{}) error { return setString(&p.Status, v) },
+	"style":                 func(p *models.Property, v interface{}) error { return setString(&p.Style, v) },
+	"yearBuilt":             func(p *models.Property, v interface{}) error { return setInt(&p.YearBuilt, v) },
+	"listingDate":           func(p *models.Property, v interface{}) error { return setTime(&p.ListingDate, v) },
+	"statusChangeTimestamp": func(p *models.Property, v interface{}) error { return setTime(&p.StatusChangeTimestamp, v) },
+	"propertyType":          func(p *models.Property, v interface{}) error { return setString(&p.PropertyType, v) },
+	"listingType":           func(p *models.Property, v interface{}) error { return setString(&p.ListingType, v) },
+	"modifiedDate":          func(p *models.Property, v interface{}) error { return setTime(&p.ModifiedDate, v) },
+	"lastUpdated":           func(p *models.Property, v interface{}) error { return setTime(&p.LastUpdated, v) },
+}
+
+// setString sets a string, numbers are formatted and lists such as
+// ArchitecturalStyle are joined with commas
+func setString(dst *string, v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		*dst = v
+	case json.Number:
+		*dst = v.String()
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			var s string
+			if err := setString(&s, item); err != nil {
+				return err
+			}
+			values = append(values, s)
+		}
+		*dst = strings.Join(values, ",")
+	default:
+		return fmt.Errorf("unexpected value %v", v)
+	}
+	return nil
+}
+
+func setFloat(dst *float64, v interface{}) error {
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("unexpected value %v", v)
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", s)
+	}
+	*dst = f
+	return nil
+}
+
+func setInt(dst *int, v interface{}) error {
+	var f float64
+	if err := setFloat(&f, v); err != nil {
+		return err
+	}
+	*dst = int(f)
+	return nil
+}
+
+// setTime sets a Unix timestamp from an ISO 8601 date or timestamp
+func setTime(dst *int64, v interface{}) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("unexpected value %v", v)
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			*dst = t.Unix()
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid date %q", s)
+}
@@ -0,0 +1,131 @@
+package reso
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+func decodeRecord(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+	var record map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&record); err != nil {
+		t.Fatalf("failed to decode record: %v", err)
+	}
+	return record
+}
+
+func TestMapping_Property(t *testing.T) {
+	record := decodeRecord(t, `{
+		"ListingKey": "abc123",
+		"UnparsedAddress": "9 Thorncrest Ridge",
+		"City": "Danbury",
+		"StateOrProvince": "CT",
+		"PostalCode": "06810",
+		"CountyOrParish": "Fairfield",
+		"Latitude": 41.378204,
+		"Longitude": -73.471196,
+		"BathroomsTotalInteger": 4,
+		"BathroomsFull": 3,
+		"BathroomsHalf": 1,
+		"BedroomsTotal": 4,
+		"ListPrice": 899000,
+		"ClosePrice": 905000.50,
+		"LivingArea": "3486",
+		"StandardStatus": "Active Under Contract",
+		"ArchitecturalStyle": ["Colonial", "Farm House"],
+		"YearBuilt": 2024,
+		"ListingContractDate": "2025-02-13",
+		"CloseDate": null,
+		"ModificationTimestamp": "2025-02-15T12:30:00Z",
+		"PropertySubType": "Single Family Residence",
+		"InternetAutomatedValuationDisplayYN": true
+	}`)
+
+	got, err := DefaultMapping().Property(record)
+	if err != nil {
+		t.Fatalf("Property() error = %v", err)
+	}
+
+	want := models.Property{
+		ID:           "abc123",
+		Address:      models.Address{Street: "9 Thorncrest Ridge", City: "Danbury", State: "CT", Zip: "06810"},
+		County:       "Fairfield",
+		Coordinates:  models.Coordinates{Latitude: 41.378204, Longitude: -73.471196},
+		Baths:        models.Bathroom{Total: 4, Full: 3, Half: 1},
+		Beds:         4,
+		ListPrice:    899000,
+		SalePrice:    905000.50,
+		Size:         3486,
+		Status:       "Under Contract",
+		Style:        "Colonial,Farm House",
+		YearBuilt:    2024,
+		ListingDate:  1739404800,
+		PropertyType: "Single Family Residence",
+		ModifiedDate: 1739622600,
+	}
+	if got != want {
+		t.Errorf("Property() = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestMapping_PropertyErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid number": `{"LivingArea": "large"}`,
+		"invalid date":   `{"CloseDate": "yesterday"}`,
+		"date as number": `{"CloseDate": 1739404800}`,
+		"object value":   `{"City": {"name": "Danbury"}}`,
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DefaultMapping().Property(decodeRecord(t, data)); err == nil {
+				t.Error("Property() expected an error")
+			}
+		})
+	}
+}
+
+func TestLoadMapping(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	mapping, err := LoadMapping(write("mapping.json", `{
+		"fields": {"size": "BuildingAreaTotal", "style": "", "systemId": "ListingId"},
+		"statuses": {"Coming Soon": "Active"}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadMapping() error = %v", err)
+	}
+
+	if mapping.Fields["size"] != "BuildingAreaTotal" || mapping.Fields["systemId"] != "ListingId" {
+		t.Errorf("LoadMapping() fields = %v, want the overrides applied", mapping.Fields)
+	}
+	if _, ok := mapping.Fields["style"]; ok {
+		t.Error("LoadMapping() kept a field mapped to an empty string")
+	}
+	if mapping.Fields["address.city"] != "City" || mapping.Statuses["Closed"] != "Closed" || mapping.Statuses["Coming Soon"] != "Active" {
+		t.Errorf("LoadMapping() = %+v, want the defaults merged with the overrides", mapping)
+	}
+
+	for name, content := range map[string]string{
+		"unknown field":    `{"fields": {"garage": "GarageSpaces"}}`,
+		"unknown property": `{"columns": {}}`,
+	} {
+		if _, err := LoadMapping(write(name+".json", content)); err == nil {
+			t.Errorf("LoadMapping() with %s expected an error", name)
+		}
+	}
+}
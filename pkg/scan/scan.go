@@ -0,0 +1,148 @@
+package scan
+
+import (
+	"math"
+	"sort"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/batch"
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+// Confidence rates an estimate by its number of comparables and the spread
+// of their prices
+type Confidence string
+
+const (
+	ConfidenceLow    Confidence = "low"
+	ConfidenceMedium Confidence = "medium"
+	ConfidenceHigh   Confidence = "high"
+)
+
+// Position tells whether a list price is below, within or above the
+// estimated range
+const (
+	PositionUnder = "under"
+	PositionFair  = "fair"
+	PositionOver  = "over"
+)
+
+// Listing is an active listing valued against the closed sales
+type Listing struct {
+	ID          string         `json:"id"`
+	Address     models.Address `json:"address"`
+	ListPrice   float64        `json:"listPrice"`
+	Value       float64        `json:"value"`
+	Low         float64        `json:"low"`
+	High        float64        `json:"high"`
+	Recommended float64        `json:"recommendedListPrice"`
+	Gap         float64        `json:"gap"`
+	GapRatio    float64        `json:"gapRatio"`
+	Position    string         `json:"position"`
+	Comparables int            `json:"comparables"`
+	Confidence  Confidence     `json:"confidence"`
+}
+
+// Run values every active listing for sale with a list price against the
+// closed sales of the listings and returns the valued ones ranked by the gap
+// between their list price and the estimate, the largest first. The summary
+// counts every active listing, including those without comparables.
+func Run(cfg *config.Config, listings []models.Property, workers int) ([]Listing, batch.Summary, error) {
+	var active, closed []models.Property
+	for _, p := range listings {
+		if p.IsLease() {
+			continue
+		}
+		switch {
+		case p.Status == "Active" && p.ListPrice > 0:
+			active = append(active, p)
+		case p.Status == "Closed":
+			closed = append(closed, p)
+		}
+	}
+
+	subjects := make(chan batch.Subject)
+	go func() {
+		defer close(subjects)
+		for i, p := range active {
+			subjects <- batch.Subject{Index: i, Property: p}
+		}
+	}()
+
+	var result []Listing
+	runner := batch.NewRunner(cfg, closed, workers)
+	summary, err := runner.Run(subjects, func(r batch.Result) error {
+		if r.Error == "" && r.Value > 0 {
+			result = append(result, newListing(active[r.Index], r))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, summary, err
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return math.Abs(result[i].GapRatio) > math.Abs(result[j].GapRatio)
+	})
+	return result, summary, nil
+}
+
+func newListing(p models.Property, r batch.Result) Listing {
+	l := Listing{
+		ID:          p.ID,
+		Address:     p.Address,
+		ListPrice:   p.ListPrice,
+		Value:       r.Value,
+		Low:         r.Low,
+		High:        r.High,
+		Recommended: math.Round(r.Value/1000) * 1000,
+		Gap:         p.ListPrice - r.Value,
+		GapRatio:    (p.ListPrice - r.Value) / r.Value,
+		Position:    PositionFair,
+		Comparables: r.Comparables,
+		Confidence:  Rate(r.Comparables, r.Value, r.Low, r.High),
+	}
+
+	switch {
+	case p.ListPrice < r.Low:
+		l.Position = PositionUnder
+	case p.ListPrice > r.High:
+		l.Position = PositionOver
+	}
+	return l
+}
+
+// Rate returns the confidence of an estimate: high with at least 5
+// comparables within 10% of it, medium with at least 3 within 20%, low
+// otherwise. The spread is half the estimated range relative to the value.
+func Rate(comparables int, value, low, high float64) Confidence {
+	if value <= 0 {
+		return ConfidenceLow
+	}
+
+	spread := (high - low) / 2 / value
+	switch {
+	case comparables >= 5 && spread <= 0.10:
+		return ConfidenceHigh
+	case comparables >= 3 && spread <= 0.20:
+		return ConfidenceMedium
+	default:
+		return ConfidenceLow
+	}
+}
+
+// AtLeast reports whether the confidence is the same as or above min
+func (c Confidence) AtLeast(min Confidence) bool {
+	return c.rank() >= min.rank()
+}
+
+func (c Confidence) rank() int {
+	switch c {
+	case ConfidenceHigh:
+		return 2
+	case ConfidenceMedium:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,115 @@
+package scan
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+func listing(id, status string, size, price float64) models.Property {
+	oneMonthAgo := time.Now().Unix() - (30 * 24 * 60 * 60)
+	p := models.Property{
+		ID:                    id,
+		Address:               models.Address{City: "Danbury", Street: id + " Main St", Zip: "06810"},
+		Size:                  size,
+		Beds:                  4,
+		Baths:                 models.Bathroom{Total: 2.5},
+		Status:                status,
+		ListingDate:           oneMonthAgo,
+		StatusChangeTimestamp: oneMonthAgo,
+		ListPrice:             price,
+	}
+	if status == "Closed" {
+		p.SalePrice = price
+	}
+	return p
+}
+
+func TestRun(t *testing.T) {
+	cfg := &config.Config{MinSalesCount: 1}
+	cfg.CriteriaWeights.Status = 1.0
+	cfg.StatusScores.Sold = 1.0
+
+	var listings []models.Property
+	for i := 0; i < 5; i++ {
+		listings = append(listings, listing(fmt.Sprint(i), "Closed", 2000, 500000+float64(i)*10000))
+	}
+	listings = append(listings,
+		listing("fair", "Active", 2000, 520000),
+		listing("over", "Active", 2000, 700000),
+		listing("under", "Active", 2000, 400000),
+		listing("no-comps", "Active", 5000, 900000),
+		listing("no-price", "Active", 2000, 0),
+		listing("pending", "Under Contract", 2000, 600000),
+	)
+
+	results, summary, err := Run(cfg, listings, 2)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if summary.Total != 4 || summary.Valued != 3 || summary.NoComparables != 1 {
+		t.Errorf("summary = %+v, want 4 active listings, 3 valued, 1 without comparables", summary)
+	}
+
+	want := []struct {
+		id       string
+		position string
+	}{
+		{id: "over", position: PositionOver},
+		{id: "under", position: PositionUnder},
+		{id: "fair", position: PositionFair},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Run() returned %d listings, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.ID != w.id || r.Position != w.position {
+			t.Errorf("results[%d] = %s %s, want %s %s", i, r.ID, r.Position, w.id, w.position)
+		}
+		if r.Value != 520000 || r.Comparables != 5 || r.Confidence != ConfidenceHigh {
+			t.Errorf("results[%d] = %+v, want value 520000 from 5 comparables with high confidence", i, r)
+		}
+		if r.Gap != r.ListPrice-r.Value || r.Recommended != 520000 {
+			t.Errorf("results[%d] gap %v, recommended %v, want %v, 520000", i, r.Gap, r.Recommended, r.ListPrice-r.Value)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		comparables int
+		value       float64
+		low, high   float64
+		want        Confidence
+	}{
+		{name: "many close comparables", comparables: 6, value: 500000, low: 460000, high: 540000, want: ConfidenceHigh},
+		{name: "many spread comparables", comparables: 6, value: 500000, low: 420000, high: 580000, want: ConfidenceMedium},
+		{name: "few close comparables", comparables: 3, value: 500000, low: 490000, high: 510000, want: ConfidenceMedium},
+		{name: "widely spread comparables", comparables: 8, value: 500000, low: 300000, high: 700000, want: ConfidenceLow},
+		{name: "one comparable", comparables: 1, value: 500000, low: 500000, high: 500000, want: ConfidenceLow},
+		{name: "no estimate", comparables: 0, want: ConfidenceLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Rate(tt.comparables, tt.value, tt.low, tt.high); got != tt.want {
+				t.Errorf("Rate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfidence_AtLeast(t *testing.T) {
+	if !ConfidenceHigh.AtLeast(ConfidenceMedium) || !ConfidenceLow.AtLeast(ConfidenceLow) {
+		t.Error("AtLeast() = false for a confidence at or above the minimum")
+	}
+	if ConfidenceMedium.AtLeast(ConfidenceHigh) {
+		t.Error("AtLeast() = true for a confidence below the minimum")
+	}
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/algorithm"
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+// DefaultSource is the name of the listings source used when a request
+// provides neither listings nor a source
+const DefaultSource = "default"
+
+const maxBodyBytes = 32 << 20
+
+// ValuationRequest is the body of POST /v1/valuations. Subject is decoded
+// strictly into models.Property, listings may carry any extra feed fields.
+type ValuationRequest struct {
+	Subject  json.RawMessage `json:"subject"`
+	Listings json.RawMessage `json:"listings,omitempty"`
+	Source   string          `json:"source,omitempty"`
+	Profile  string          `json:"profile,omitempty"`
+	Config   json.RawMessage `json:"config,omitempty"`
+}
+
+// FieldError describes a problem with a single request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Problem string `json:"problem"`
+}
+
+// ErrorResponse is returned with every non-2xx status
+type ErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// Server exposes the valuation algorithm over HTTP
+type Server struct {
+	Config  *config.Config
+	Sources map[string][]models.Property
+	mux     *http.ServeMux
+}
+
+func NewServer(cfg *config.Config, sources map[string][]models.Property) *Server {
+	s := &Server{
+		Config:  cfg,
+		Sources: sources,
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /v1/healthz", s.handleHealth)
+	s.mux.HandleFunc("POST /v1/valuations", s.handleValuation)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleValuation(w http.ResponseWriter, r *http.Request) {
+	var req ValuationRequest
+	if err := decodeStrict(http.MaxBytesReader(w, r.Body, maxBodyBytes), &req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrorResponse{Error: "invalid request body: " + err.Error()})
+		return
+	}
+
+	subject, fieldErrs := decodeSubject(req.Subject)
+	if len(fieldErrs) > 0 {
+		writeError(w, http.StatusBadRequest, ErrorResponse{Error: "invalid subject", Fields: fieldErrs})
+		return
+	}
+
+	listings, err := s.listings(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	profile := req.Profile
+	if profile == "" {
+		profile = s.Config.SelectProfile(algorithm.Location(subject))
+	}
+
+	cfg, err := s.Config.Profile(profile)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	cfg, err = cfg.Override(req.Config)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, configError(err))
+		return
+	}
+
+	valuation, err := algorithm.NewValuationWithProfile(subject, listings, cfg, config.DefaultProfile)
+	if err != nil {
+		log.Printf("Error creating valuation: %v", err)
+		writeError(w, http.StatusInternalServerError, ErrorResponse{Error: "internal error"})
+		return
+	}
+
+	result := valuation.Estimate()
+	result.Profile = profile
+	if result.Comparables == nil {
+		result.Comparables = []algorithm.Comparable{}
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// listings returns the inline listings of the request or the named source
+func (s *Server) listings(req ValuationRequest) ([]models.Property, error) {
+	if len(req.Listings) > 0 && req.Source != "" {
+		return nil, errors.New("listings and source are mutually exclusive")
+	}
+
+	if len(req.Listings) > 0 {
+		var listings []models.Property
+		if err := json.Unmarshal(req.Listings, &listings); err != nil {
+			return nil, fmt.Errorf("invalid listings: %v", err)
+		}
+		return listings, nil
+	}
+
+	name := req.Source
+	if name == "" {
+		name = DefaultSource
+	}
+
+	listings, ok := s.Sources[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown listings source %q", name)
+	}
+	return listings, nil
+}
+
+// decodeSubject decodes the subject property and checks the fields the
+// valuation depends on
+func decodeSubject(raw json.RawMessage) (models.Property, []FieldError) {
+	var subject models.Property
+
+	if len(raw) == 0 {
+		return subject, []FieldError{{"subject", "is required"}}
+	}
+	if err := decodeStrict(bytes.NewReader(raw), &subject); err != nil {
+		return subject, []FieldError{{"subject", err.Error()}}
+	}
+
+	var errs []FieldError
+	if subject.Address.City == "" {
+		errs = append(errs, FieldError{"subject.address.city", "is required"})
+	}
+	if subject.Size <= 0 {
+		errs = append(errs, FieldError{"subject.size", "must be greater than 0"})
+	}
+	if subject.Beds < 0 {
+		errs = append(errs, FieldError{"subject.beds", "must not be negative"})
+	}
+	if subject.Baths.Total < 0 {
+		errs = append(errs, FieldError{"subject.baths.total", "must not be negative"})
+	}
+
+	return subject, errs
+}
+
+func configError(err error) ErrorResponse {
+	var validationErr config.ValidationError
+	if !errors.As(err, &validationErr) {
+		return ErrorResponse{Error: "invalid config: " + err.Error()}
+	}
+
+	resp := ErrorResponse{Error: "invalid config"}
+	for _, fe := range validationErr {
+		resp.Fields = append(resp.Fields, FieldError{"config." + fe.Field, fe.Problem})
+	}
+	return resp
+}
+
+func decodeStrict(r io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, resp ErrorResponse) {
+	writeJSON(w, status, resp)
+}
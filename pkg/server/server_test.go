@@ -0,0 +1,195 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/algorithm"
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+func createTestConfig() *config.Config {
+	cfg := config.Defaults()
+	cfg.MinSalesCount = 1
+	return cfg
+}
+
+func createTestListings() []models.Property {
+	oneMonthAgo := time.Now().Unix() - (30 * 24 * 60 * 60)
+
+	listing := func(id string, price float64) models.Property {
+		return models.Property{
+			ID:                    id,
+			Address:               models.Address{City: "Danbury", State: "CT"},
+			Size:                  2000,
+			Beds:                  4,
+			Baths:                 models.Bathroom{Total: 2.5},
+			Style:                 "Colonial",
+			Status:                "Closed",
+			ListingDate:           oneMonthAgo,
+			StatusChangeTimestamp: oneMonthAgo,
+			ListPrice:             price,
+			SalePrice:             price,
+		}
+	}
+
+	return []models.Property{listing("1", 600000), listing("2", 600000)}
+}
+
+const testSubject = `{"address": {"city": "Danbury", "state": "CT"}, "size": 2000, "beds": 4, "baths": {"total": 2.5}, "style": "Colonial"}`
+
+func TestServer_Health(t *testing.T) {
+	srv := NewServer(createTestConfig(), nil)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %v, want %v", rec.Code, http.StatusOK)
+	}
+}
+
+func TestServer_Valuation(t *testing.T) {
+	listingsJSON, err := json.Marshal(createTestListings())
+	if err != nil {
+		t.Fatalf("Failed to marshal listings: %v", err)
+	}
+
+	srv := NewServer(createTestConfig(), map[string][]models.Property{
+		DefaultSource: createTestListings(),
+	})
+
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantValue   float64
+		wantError   string
+		wantField   string
+		wantProfile string
+	}{
+		{
+			name:        "default source",
+			body:        `{"subject": ` + testSubject + `}`,
+			wantStatus:  http.StatusOK,
+			wantValue:   600000,
+			wantProfile: config.DefaultProfile,
+		},
+		{
+			name:        "inline listings",
+			body:        `{"subject": ` + testSubject + `, "listings": ` + string(listingsJSON) + `}`,
+			wantStatus:  http.StatusOK,
+			wantValue:   600000,
+			wantProfile: config.DefaultProfile,
+		},
+		{
+			name:        "config override",
+			body:        `{"subject": ` + testSubject + `, "config": {"min_sales_count": 2}}`,
+			wantStatus:  http.StatusOK,
+			wantValue:   600000,
+			wantProfile: config.DefaultProfile,
+		},
+		{
+			name:       "invalid config override",
+			body:       `{"subject": ` + testSubject + `, "config": {"min_sales_count": 0}}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid config",
+			wantField:  "config.min_sales_count",
+		},
+		{
+			name:       "missing subject",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid subject",
+			wantField:  "subject",
+		},
+		{
+			name:       "invalid subject",
+			body:       `{"subject": {"address": {"city": "Danbury"}, "size": 0}}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid subject",
+			wantField:  "subject.size",
+		},
+		{
+			name:       "unknown subject field",
+			body:       `{"subject": {"address": {"city": "Danbury"}, "size": 2000, "sqft": 2000}}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid subject",
+			wantField:  "subject",
+		},
+		{
+			name:       "unknown request field",
+			body:       `{"subject": ` + testSubject + `, "comps": []}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid request body",
+		},
+		{
+			name:       "unknown source",
+			body:       `{"subject": ` + testSubject + `, "source": "manhattan"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "unknown listings source",
+		},
+		{
+			name:       "unknown profile",
+			body:       `{"subject": ` + testSubject + `, "profile": "manhattan"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "unknown profile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/v1/valuations", strings.NewReader(tt.body))
+			srv.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %v, want %v: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				var resp ErrorResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to decode error response: %v", err)
+				}
+				if !strings.Contains(resp.Error, tt.wantError) {
+					t.Errorf("error = %v, want it to contain %q", resp.Error, tt.wantError)
+				}
+				if tt.wantField != "" && (len(resp.Fields) == 0 || resp.Fields[0].Field != tt.wantField) {
+					t.Errorf("fields = %+v, want %v", resp.Fields, tt.wantField)
+				}
+				return
+			}
+
+			var result algorithm.Result
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if result.Value != tt.wantValue {
+				t.Errorf("value = %v, want %v", result.Value, tt.wantValue)
+			}
+			if result.Profile != tt.wantProfile {
+				t.Errorf("profile = %v, want %v", result.Profile, tt.wantProfile)
+			}
+			if len(result.Comparables) != 2 {
+				t.Errorf("got %d comparables, want 2", len(result.Comparables))
+			}
+		})
+	}
+}
+
+func TestServer_MethodNotAllowed(t *testing.T) {
+	srv := NewServer(createTestConfig(), nil)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/valuations", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %v, want %v", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
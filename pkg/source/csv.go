@@ -0,0 +1,246 @@
+package source
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+// CSVMapping maps CSV headers to the property fields they set, fields are
+// named by their JSON path in models.Property such as "address.city".
+// Headers are matched case-insensitively.
+type CSVMapping map[string]string
+
+// DefaultCSVMapping maps every header named like a property field to it
+func DefaultCSVMapping() CSVMapping {
+	mapping := CSVMapping{}
+	for field := range csvFields {
+		mapping[field] = field
+	}
+	return mapping
+}
+
+// LoadCSVMapping reads a JSON object of header to field names
+func LoadCSVMapping(path string) (CSVMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapping CSVMapping
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&mapping); err != nil {
+		return nil, fmt.Errorf("error parsing CSV mapping: %v", err)
+	}
+	for header, field := range mapping {
+		if _, ok := csvFields[field]; !ok {
+			return nil, fmt.Errorf("error parsing CSV mapping: header %q maps to unknown field %q", header, field)
+		}
+	}
+	return mapping, nil
+}
+
+// CSV reads listings from a CSV file with a header row. Prices may be
+// currency strings such as "$1,250,000", dates ISO 8601 dates or Unix
+// timestamps in seconds or milliseconds. When only the bath total is mapped
+// the full and half baths are derived from it, 3.5 is 3 full and 1 half.
+type CSV struct {
+	Path    string
+	Mapping CSVMapping
+}
+
+func (c *CSV) Listings(q store.Query) ([]models.Property, error) {
+	return collect(c, q)
+}
+
+func (c *CSV) walk(q store.Query, fn func(models.Property) bool) error {
+	file, err := os.Open(c.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := walkCSV(file, c.Mapping, q, fn); err != nil {
+		return fmt.Errorf("error parsing market listings %s: %v", c.Path, err)
+	}
+	return nil
+}
+
+// ReadCSV reads every listing of a CSV with the mapping, columns without a
+// mapping are ignored and empty cells leave the field unset
+func ReadCSV(r io.Reader, mapping CSVMapping) ([]models.Property, error) {
+	var listings []models.Property
+	err := walkCSV(r, mapping, store.Query{}, func(p models.Property) bool {
+		listings = append(listings, p)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listings, nil
+}
+
+// walkCSV reads the rows one at a time and passes the listings matching the
+// query to fn until it returns false
+func walkCSV(r io.Reader, mapping CSVMapping, q store.Query, fn func(models.Property) bool) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %v", err)
+	}
+
+	byHeader := map[string]string{}
+	for h, field := range mapping {
+		byHeader[strings.ToLower(strings.TrimSpace(h))] = field
+	}
+
+	fields := make([]string, len(header))
+	derivesBaths := false
+	for i, h := range header {
+		fields[i] = byHeader[strings.ToLower(strings.TrimSpace(h))]
+		if fields[i] == "baths.total" {
+			derivesBaths = true
+		}
+	}
+	for _, field := range fields {
+		if field == "baths.full" || field == "baths.half" {
+			derivesBaths = false
+		}
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+
+		var p models.Property
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			if i >= len(fields) || fields[i] == "" || value == "" {
+				continue
+			}
+			if err := csvFields[fields[i]](&p, value); err != nil {
+				return fmt.Errorf("line %d: column %s: %v", line, header[i], err)
+			}
+		}
+
+		if derivesBaths {
+			p.Baths.Full = int(math.Floor(p.Baths.Total))
+			if p.Baths.Total > float64(p.Baths.Full) {
+				p.Baths.Half = 1
+			}
+		}
+		if q.Matches(p) && !fn(p) {
+			return nil
+		}
+	}
+}
+
+// csvFields maps the property fields to the function parsing and setting a cell
+var csvFields = map[string]func(p *models.Property, v string) error{
+	"id":                    func(p *models.Property, v string) error { p.ID = v; return nil },
+	"systemId":              func(p *models.Property, v string) error { p.SystemID = v; return nil },
+	"address.street":        func(p *models.Property, v string) error { p.Address.Street = v; return nil },
+	"address.deliveryLine":  func(p *models.Property, v string) error { p.Address.DeliveryLine = v; return nil },
+	"address.city":          func(p *models.Property, v string) error { p.Address.City = v; return nil },
+	"address.state":         func(p *models.Property, v string) error { p.Address.State = v; return nil },
+	"address.zip":           func(p *models.Property, v string) error { p.Address.Zip = v; return nil },
+	"county":                func(p *models.Property, v string) error { p.County = v; return nil },
+	"subdivision":           func(p *models.Property, v string) error { p.Subdivision = v; return nil },
+	"schools.elementary":    func(p *models.Property, v string) error { p.Schools.Elementary = v; return nil },
+	"schools.middle":        func(p *models.Property, v string) error { p.Schools.Middle = v; return nil },
+	"schools.high":          func(p *models.Property, v string) error { p.Schools.High = v; return nil },
+	"schools.district":      func(p *models.Property, v string) error { p.Schools.District = v; return nil },
+	"coordinates.latitude":  func(p *models.Property, v string) error { return parseNumber(&p.Coordinates.Latitude, v) },
+	"coordinates.longitude": func(p *models.Property, v string) error { return parseNumber(&p.Coordinates.Longitude, v) },
+	"baths.total":           func(p *models.Property, v string) error { return parseNumber(&p.Baths.Total, v) },
+	"baths.full":            func(p *models.Property, v string) error { return parseCount(&p.Baths.Full, v) },
+	"baths.half":            func(p *models.Property, v string) error { return parseCount(&p.Baths.Half, v) },
+	"beds":                  func(p *models.Property, v string) error { return parseCount(&p.Beds, v) },
+	"listPrice":             func(p *models.Property, v string) error { return parseNumber(&p.ListPrice, v) },
+	"salePrice":             func(p *models.Property, v string) error { return parseNumber(&p.SalePrice, v) },
+	"previousListPrice":     func(p *models.Property, v string) error { return parseNumber(&p.PreviousListPrice, v) },
+	"priceChangeTimestamp":  func(p *models.Property, v string) error { return parseDate(&p.PriceChangeTimestamp, v) },
+	"daysOnMarket":          func(p *models.Property, v string) error { return parseCount(&p.DaysOnMarket, v) },
+	"size":                  func(p *models.Property, v string) error { return parseNumber(&p.Size, v) },
+	"status":                func(p *models.Property, v string) error { p.Status = v; return nil },
+	"style":                 func(p *models.Property, v string) error { p.Style = v; return nil },
+	"yearBuilt":             func(p *models.Property, v string) error { return parseCount(&p.YearBuilt, v) },
+	"listingDate":           func(p *models.Property, v string) error { return parseDate(&p.ListingDate, v) },
+	"statusChangeTimestamp": func(p *models.Property, v string) error { return parseDate(&p.StatusChangeTimestamp, v) },
+	"propertyType":          func(p *models.Property, v string) error { p.PropertyType = v; return nil },
+	"listingType":           func(p *models.Property, v string) error { p.ListingType = v; return nil },
+	"modifiedDate":          func(p *models.Property, v string) error { return parseDate(&p.ModifiedDate, v) },
+	"lastUpdated":           func(p *models.Property, v string) error { return parseDate(&p.LastUpdated, v) },
+}
+
+// parseNumber parses a number that may be written as a currency amount
+// with a dollar sign and thousands separators
+func parseNumber(dst *float64, value string) error {
+	cleaned := strings.NewReplacer("$", "", ",", "", " ", "").Replace(value)
+	v, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", value)
+	}
+	*dst = v
+	return nil
+}
+
+func parseCount(dst *int, value string) error {
+	var v float64
+	if err := parseNumber(&v, value); err != nil {
+		return err
+	}
+	if v != math.Trunc(v) {
+		return fmt.Errorf("invalid integer %q", value)
+	}
+	*dst = int(v)
+	return nil
+}
+
+// epochMillisThreshold separates Unix timestamps in milliseconds from seconds
+const epochMillisThreshold = 100_000_000_000
+
+// parseDate parses an ISO 8601 date or timestamp, a basic format date such
+// as 20250213 or a Unix timestamp
+func parseDate(dst *int64, value string) error {
+	// 8 digits are a basic format date before they are a timestamp in 1973
+	if len(value) == 8 {
+		if t, err := time.Parse("20060102", value); err == nil {
+			*dst = t.Unix()
+			return nil
+		}
+	}
+
+	if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if v > epochMillisThreshold {
+			v /= 1000
+		}
+		*dst = v
+		return nil
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			*dst = t.Unix()
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid date %q", value)
+}
@@ -0,0 +1,141 @@
+package source
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+var testCSVMapping = CSVMapping{
+	"MLS #":      "id",
+	"Town":       "address.city",
+	"Status":     "status",
+	"Close Date": "statusChangeTimestamp",
+	"List Price": "listPrice",
+	"Sold Price": "salePrice",
+	"SqFt":       "size",
+	"Beds":       "beds",
+	"Baths":      "baths.total",
+}
+
+func TestReadCSV(t *testing.T) {
+	data := `mls #,Town,Status,Close Date,List Price,Sold Price,SqFt,Beds,Baths,Agent
+1,Danbury,Closed,2025-02-13,"$700,000","$ 710,000.50","2,800",4,3.5,Jane
+2,Danbury,Closed,1739404800000,650000,640000,2700,4,3,John
+3,Norwalk,Active,,"$690,000",,2650,3,2.5
+`
+
+	got, err := ReadCSV(strings.NewReader(data), testCSVMapping)
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+
+	want := []models.Property{
+		{ID: "1", Address: models.Address{City: "Danbury"}, Status: "Closed", StatusChangeTimestamp: 1739404800,
+			ListPrice: 700000, SalePrice: 710000.50, Size: 2800, Beds: 4, Baths: models.Bathroom{Total: 3.5, Full: 3, Half: 1}},
+		{ID: "2", Address: models.Address{City: "Danbury"}, Status: "Closed", StatusChangeTimestamp: 1739404800,
+			ListPrice: 650000, SalePrice: 640000, Size: 2700, Beds: 4, Baths: models.Bathroom{Total: 3, Full: 3}},
+		{ID: "3", Address: models.Address{City: "Norwalk"}, Status: "Active",
+			ListPrice: 690000, Size: 2650, Beds: 3, Baths: models.Bathroom{Total: 2.5, Full: 2, Half: 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadCSV() = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestReadCSV_BathColumns(t *testing.T) {
+	mapping := CSVMapping{"total": "baths.total", "full": "baths.full", "half": "baths.half"}
+
+	got, err := ReadCSV(strings.NewReader("total,full,half\n3.5,2,3\n"), mapping)
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+
+	// mapped full and half baths are not derived from the total
+	if want := (models.Bathroom{Total: 3.5, Full: 2, Half: 3}); got[0].Baths != want {
+		t.Errorf("ReadCSV() baths = %+v, want %+v", got[0].Baths, want)
+	}
+}
+
+func TestReadCSV_Errors(t *testing.T) {
+	tests := map[string]string{
+		"empty file":       "",
+		"invalid price":    "List Price\nabout 500k\n",
+		"fractional beds":  "Beds\n3.5\n",
+		"invalid date":     "Close Date\n13/02/2025\n",
+		"unbalanced quote": "Town\n\"Danbury\n",
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadCSV(strings.NewReader(data), testCSVMapping); err == nil {
+				t.Error("ReadCSV() expected an error")
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := map[string]int64{
+		"2025-02-13":           1739404800,
+		"2025-02-13T00:00:00":  1739404800,
+		"2025-02-13T00:00:00Z": 1739404800,
+		"20250213":             1739404800,
+		"1739404800":           1739404800,
+		"1739404800000":        1739404800,
+		// not a valid basic format date
+		"99999999": 99999999,
+	}
+
+	for value, want := range tests {
+		t.Run(value, func(t *testing.T) {
+			var got int64
+			if err := parseDate(&got, value); err != nil || got != want {
+				t.Errorf("parseDate(%q) = %v, %v, want %v", value, got, err, want)
+			}
+		})
+	}
+}
+
+func TestCSV_Listings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "listings.csv")
+	writeTestFile(t, path, "id,address.city,size\n1,Danbury,2000\n2,Norwalk,2000\n")
+
+	src, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	listings, err := src.Listings(store.Query{City: "Danbury"})
+	if err != nil {
+		t.Fatalf("Listings() error = %v", err)
+	}
+	if got := ids(listings); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Listings() = %v, want [1]", got)
+	}
+}
+
+func TestLoadCSVMapping(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.json")
+	writeTestFile(t, valid, `{"Town": "address.city", "SqFt": "size"}`)
+	mapping, err := LoadCSVMapping(valid)
+	if err != nil {
+		t.Fatalf("LoadCSVMapping() error = %v", err)
+	}
+	if want := (CSVMapping{"Town": "address.city", "SqFt": "size"}); !reflect.DeepEqual(mapping, want) {
+		t.Errorf("LoadCSVMapping() = %v, want %v", mapping, want)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	writeTestFile(t, invalid, `{"Garage": "garageSpaces"}`)
+	if _, err := LoadCSVMapping(invalid); err == nil {
+		t.Error("LoadCSVMapping() with an unknown field expected an error")
+	}
+}
@@ -0,0 +1,140 @@
+package source
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+// Decoder reads listings one at a time from a JSON array or a stream of
+// JSON listings, so only the listings matching its query are ever kept in
+// memory. The format is detected from the first character of the input.
+type Decoder struct {
+	// Query is applied to every listing as it is decoded
+	Query store.Query
+
+	reader  *bufio.Reader
+	dec     *json.Decoder
+	started bool
+	array   bool
+	done    bool
+	index   int
+}
+
+// NewDecoder creates a decoder returning the listings matching the query
+func NewDecoder(r io.Reader, q store.Query) *Decoder {
+	return &Decoder{Query: q, reader: bufio.NewReader(r)}
+}
+
+// Next returns the next listing matching the query, io.EOF after the last one
+func (d *Decoder) Next() (models.Property, error) {
+	if err := d.start(); err != nil {
+		return models.Property{}, err
+	}
+	if d.done {
+		return models.Property{}, io.EOF
+	}
+
+	for {
+		if d.array && !d.dec.More() {
+			return models.Property{}, d.end()
+		}
+
+		var p models.Property
+		err := d.dec.Decode(&p)
+		if err == io.EOF && !d.array {
+			d.done = true
+			return models.Property{}, io.EOF
+		}
+		if err != nil {
+			return models.Property{}, fmt.Errorf("listing %d: %v", d.index+1, err)
+		}
+		d.index++
+
+		if d.Query.Matches(p) {
+			return p, nil
+		}
+	}
+}
+
+// start detects the format and consumes the opening bracket of an array
+func (d *Decoder) start() error {
+	if d.started {
+		return nil
+	}
+	d.started = true
+
+	for {
+		b, err := d.reader.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if b == ' ' || b == '\t' || b == '\r' || b == '\n' {
+			continue
+		}
+		d.reader.UnreadByte()
+		d.array = b == '['
+		break
+	}
+
+	d.dec = json.NewDecoder(d.reader)
+	if d.array {
+		if _, err := d.dec.Token(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// end consumes the closing bracket of an array and checks nothing follows it
+func (d *Decoder) end() error {
+	if _, err := d.dec.Token(); err != nil {
+		return fmt.Errorf("listing %d: %v", d.index+1, err)
+	}
+	if _, err := d.dec.Token(); err != io.EOF {
+		return errors.New("unexpected data after the listings array")
+	}
+	d.done = true
+	return io.EOF
+}
+
+// decode returns every listing of the reader matching the query
+func decode(r io.Reader, q store.Query) ([]models.Property, error) {
+	return decodeAll(NewDecoder(r, q))
+}
+
+func decodeAll(d *Decoder) ([]models.Property, error) {
+	var listings []models.Property
+	err := decodeEach(d, func(p models.Property) bool {
+		listings = append(listings, p)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listings, nil
+}
+
+// decodeEach passes the listings of the decoder to fn until it returns false
+func decodeEach(d *Decoder, fn func(models.Property) bool) error {
+	for {
+		p, err := d.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !fn(p) {
+			return nil
+		}
+	}
+}
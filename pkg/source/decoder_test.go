@@ -0,0 +1,140 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+const decoderTestListings = `
+	{"id": "1", "address": {"city": "Danbury"}, "status": "Closed", "statusChangeTimestamp": 1700000000},
+	{"id": "2", "address": {"city": "Danbury"}, "status": "Closed", "statusChangeTimestamp": 1710000000},
+	{"id": "3", "address": {"city": "Danbury"}, "status": "Active", "statusChangeTimestamp": 1710000000},
+	{"id": "4", "address": {"city": "Norwalk"}, "status": "Closed", "statusChangeTimestamp": 1710000000}
+`
+
+func decodeIDs(t *testing.T, input string, q store.Query) []string {
+	t.Helper()
+	d := NewDecoder(strings.NewReader(input), q)
+
+	got := []string{}
+	for {
+		p, err := d.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		got = append(got, p.ID)
+	}
+
+	if _, err := d.Next(); err != io.EOF {
+		t.Errorf("Next() after the last listing error = %v, want io.EOF", err)
+	}
+	return got
+}
+
+func TestDecoder(t *testing.T) {
+	array := "[" + decoderTestListings + "]\n"
+	stream := strings.ReplaceAll(decoderTestListings, "},\n", "}\n")
+
+	tests := []struct {
+		name  string
+		input string
+		query store.Query
+		want  []string
+	}{
+		{name: "array", input: array, want: []string{"1", "2", "3", "4"}},
+		{name: "stream", input: stream, want: []string{"1", "2", "3", "4"}},
+		{name: "empty array", input: " [ ] ", want: []string{}},
+		{name: "empty input", input: "\n", want: []string{}},
+		{name: "city", input: array, query: store.Query{City: "Danbury"}, want: []string{"1", "2", "3"}},
+		{name: "status", input: stream, query: store.Query{Statuses: []string{"Active"}}, want: []string{"3"}},
+		{name: "sale date window", input: array, query: store.Query{City: "Danbury", SoldAfter: 1705000000, SoldBefore: 1715000000}, want: []string{"2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeIDs(t, tt.input, tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decoded %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	tests := map[string]string{
+		"truncated array":     `[{"id": "1"}, {"id": "2"`,
+		"missing bracket":     `[{"id": "1"}`,
+		"invalid listing":     `[{"id": "1"}, {"id": 2}]`,
+		"data after array":    `[{"id": "1"}] {"id": "2"}`,
+		"invalid stream":      `{"id": "1"} nonsense`,
+		"array of non-object": `["1"]`,
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(input), store.Query{})
+			for {
+				_, err := d.Next()
+				if err == io.EOF {
+					t.Fatal("Next() reached the end without an error")
+				}
+				if err != nil {
+					return
+				}
+			}
+		})
+	}
+}
+
+// feedReader generates a JSON array of listings without holding it in memory
+func feedReader(n int) io.Reader {
+	r, w := io.Pipe()
+	go func() {
+		cities := []string{"Danbury", "Norwalk", "Stamford", "Bethel"}
+		io.WriteString(w, "[")
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				io.WriteString(w, ",")
+			}
+			fmt.Fprintf(w, `{"id":"%d","address":{"city":%q},"size":2000,"status":"Closed","description":%q}`,
+				i, cities[i%len(cities)], strings.Repeat("x", 512))
+		}
+		io.WriteString(w, "]")
+		w.Close()
+	}()
+	return r
+}
+
+func TestDecoder_LargeFeed(t *testing.T) {
+	listings, err := decode(feedReader(20000), store.Query{City: "Bethel"})
+	if err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	if len(listings) != 5000 {
+		t.Errorf("decode() kept %d listings, want 5000", len(listings))
+	}
+}
+
+func BenchmarkDecoder(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d := NewDecoder(feedReader(10000), store.Query{City: "Bethel"})
+		var p models.Property
+		var err error
+		for err == nil {
+			p, err = d.Next()
+		}
+		if err != io.EOF {
+			b.Fatal(err)
+		}
+		_ = p
+	}
+}
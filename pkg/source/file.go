@@ -0,0 +1,111 @@
+package source
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+// File reads listings from a file holding a JSON array
+type File struct {
+	Path string
+}
+
+func (f *File) Listings(q store.Query) ([]models.Property, error) {
+	return collect(f, q)
+}
+
+func (f *File) walk(q store.Query, fn func(models.Property) bool) error {
+	_, err := walkFile(f.Path, q, true, fn)
+	return err
+}
+
+// JSONL reads listings from a file holding one JSON listing per line
+type JSONL struct {
+	Path string
+}
+
+func (j *JSONL) Listings(q store.Query) ([]models.Property, error) {
+	return collect(j, q)
+}
+
+func (j *JSONL) walk(q store.Query, fn func(models.Property) bool) error {
+	_, err := walkFile(j.Path, q, false, fn)
+	return err
+}
+
+// Dir reads the listings of every .json, .jsonl and .ndjson file in a
+// directory in name order, subdirectories are not read
+type Dir struct {
+	Path string
+}
+
+func (d *Dir) Listings(q store.Query) ([]models.Property, error) {
+	return collect(d, q)
+}
+
+func (d *Dir) walk(q store.Query, fn func(models.Property) bool) error {
+	entries, err := os.ReadDir(d.Path)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if isJSONL(e.Name()) || strings.EqualFold(filepath.Ext(e.Name()), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := filepath.Join(d.Path, name)
+
+		stopped, err := walkFile(path, q, !isJSONL(name), fn)
+		if err != nil || stopped {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkFile streams the listings of a file holding a JSON array or one
+// listing per line and passes those matching the query to fn, it reports
+// whether fn stopped the walk
+func walkFile(path string, q store.Query, array bool, fn func(models.Property) bool) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	d := NewDecoder(file, q)
+	if err := d.start(); err != nil {
+		return false, fmt.Errorf("error parsing market listings %s: %v", path, err)
+	}
+	if d.array != array {
+		format := "one JSON listing per line"
+		if array {
+			format = "a JSON array"
+		}
+		return false, fmt.Errorf("error parsing market listings %s: expected %s", path, format)
+	}
+
+	stopped := false
+	err = decodeEach(d, func(p models.Property) bool {
+		stopped = !fn(p)
+		return !stopped
+	})
+	if err != nil {
+		return false, fmt.Errorf("error parsing market listings %s: %v", path, err)
+	}
+	return stopped, nil
+}
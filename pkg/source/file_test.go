@@ -0,0 +1,78 @@
+package source
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+func ids(listings []models.Property) []string {
+	result := []string{}
+	for _, l := range listings {
+		result = append(result, l.ID)
+	}
+	return result
+}
+
+func TestFileSources(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a.json"), `[
+		{"id": "1", "address": {"city": "Danbury"}, "size": 2000, "agent": "ignored"},
+		{"id": "2", "address": {"city": "Norwalk"}, "size": 2000}
+	]`)
+	writeTestFile(t, filepath.Join(dir, "b.jsonl"), `{"id": "3", "address": {"city": "Danbury"}, "size": 1000}
+
+{"id": "4", "address": {"city": "Danbury"}, "size": 2500}
+`)
+	writeTestFile(t, filepath.Join(dir, "notes.txt"), "not listings")
+
+	danbury := store.Query{City: "Danbury"}
+
+	tests := []struct {
+		name  string
+		src   ListingSource
+		query store.Query
+		want  []string
+	}{
+		{name: "json file", src: &File{Path: filepath.Join(dir, "a.json")}, query: danbury, want: []string{"1"}},
+		{name: "jsonl file", src: &JSONL{Path: filepath.Join(dir, "b.jsonl")}, want: []string{"3", "4"}},
+		{name: "jsonl file with size range", src: &JSONL{Path: filepath.Join(dir, "b.jsonl")}, query: store.Query{MinSize: 1500}, want: []string{"4"}},
+		{name: "directory", src: &Dir{Path: dir}, query: danbury, want: []string{"1", "3", "4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listings, err := tt.src.Listings(tt.query)
+			if err != nil {
+				t.Fatalf("Listings() error = %v", err)
+			}
+			if got := ids(listings); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Listings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileSources_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "bad.json"), `{"id": "1"}`)
+	writeTestFile(t, filepath.Join(dir, "bad.jsonl"), "{\"id\": \"1\"}\n{\"id\": 2}\n")
+
+	sources := map[string]ListingSource{
+		"object instead of array": &File{Path: filepath.Join(dir, "bad.json")},
+		"invalid jsonl listing":   &JSONL{Path: filepath.Join(dir, "bad.jsonl")},
+		"missing file":            &File{Path: filepath.Join(dir, "missing.json")},
+		"directory with bad file": &Dir{Path: dir},
+	}
+
+	for name, src := range sources {
+		t.Run(name, func(t *testing.T) {
+			if _, err := All(src); err == nil {
+				t.Error("All() expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package source
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+// HTTP fetches listings from a URL serving a JSON array or one JSON listing per line
+type HTTP struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewHTTP creates an HTTP source with a client that times out after a minute
+func NewHTTP(url string) *HTTP {
+	return &HTTP{
+		URL:    url,
+		Client: &http.Client{Timeout: time.Minute},
+	}
+}
+
+func (h *HTTP) Listings(q store.Query) ([]models.Property, error) {
+	return collect(h, q)
+}
+
+func (h *HTTP) walk(q store.Query, fn func(models.Property) bool) error {
+	resp, err := h.Client.Get(h.URL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch listings: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch listings: %s returned %s", h.URL, resp.Status)
+	}
+
+	if err := decodeEach(NewDecoder(resp.Body, q), fn); err != nil {
+		return fmt.Errorf("error parsing market listings from %s: %v", h.URL, err)
+	}
+	return nil
+}
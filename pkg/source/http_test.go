@@ -0,0 +1,50 @@
+package source
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+func TestHTTP_Listings(t *testing.T) {
+	bodies := map[string]string{
+		"/array": `[{"id": "1", "address": {"city": "Danbury"}}, {"id": "2", "address": {"city": "Norwalk"}}]`,
+		"/jsonl": "{\"id\": \"1\", \"address\": {\"city\": \"Danbury\"}}\n{\"id\": \"2\", \"address\": {\"city\": \"Danbury\"}}\n",
+		"/empty": "  \n",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{path: "/array", want: []string{"1"}},
+		{path: "/jsonl", want: []string{"1", "2"}},
+		{path: "/empty", want: []string{}},
+		{path: "/missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			listings, err := NewHTTP(server.URL + tt.path).Listings(store.Query{City: "Danbury"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Listings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := ids(listings); !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Listings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
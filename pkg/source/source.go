@@ -0,0 +1,118 @@
+package source
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+	"github.com/krlosmederos/locqube-challenge/pkg/store"
+)
+
+// ListingSource provides the market listings comparables are picked from.
+// Listings must return at least every listing matching the query, sources
+// that cannot filter may return more.
+type ListingSource interface {
+	Listings(q store.Query) ([]models.Property, error)
+}
+
+// Open returns the source for a location. http:// and https:// URLs are
+// fetched with HTTP, file:// URLs and plain paths are read as a directory of
+// listing files, a JSONL file (.jsonl or .ndjson), a CSV file with the
+// default mapping or a JSON array file.
+func Open(location string) (ListingSource, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid listings location %q: %v", location, err)
+	}
+
+	path := location
+	switch u.Scheme {
+	case "http", "https":
+		return NewHTTP(location), nil
+	case "file":
+		path = u.Host + u.Path
+	case "":
+	default:
+		return nil, fmt.Errorf("unsupported listings location scheme %q", u.Scheme)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &Dir{Path: path}, nil
+	}
+	if isJSONL(path) {
+		return &JSONL{Path: path}, nil
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return &CSV{Path: path, Mapping: DefaultCSVMapping()}, nil
+	}
+	return &File{Path: path}, nil
+}
+
+// All returns every listing of the source
+func All(src ListingSource) ([]models.Property, error) {
+	return src.Listings(store.Query{})
+}
+
+// walker is a source that passes its listings one at a time as they are
+// read, fn returns false to stop reading
+type walker interface {
+	walk(q store.Query, fn func(models.Property) bool) error
+}
+
+// collect returns the listings of a walker matching the query
+func collect(w walker, q store.Query) ([]models.Property, error) {
+	var listings []models.Property
+	err := w.walk(q, func(p models.Property) bool {
+		listings = append(listings, p)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listings, nil
+}
+
+// Find returns the listing of the source with the ID or system ID and
+// whether there is one. The file, directory, CSV and HTTP sources are read
+// up to the listing only, other sources are read whole.
+func Find(src ListingSource, id string) (models.Property, bool, error) {
+	matches := func(p models.Property) bool {
+		return p.ID == id || p.SystemID == id
+	}
+
+	if w, ok := src.(walker); ok {
+		var found models.Property
+		var ok bool
+		err := w.walk(store.Query{}, func(p models.Property) bool {
+			found, ok = p, matches(p)
+			return !ok
+		})
+		if err != nil || !ok {
+			return models.Property{}, false, err
+		}
+		return found, true, nil
+	}
+
+	listings, err := All(src)
+	if err != nil {
+		return models.Property{}, false, err
+	}
+	for _, p := range listings {
+		if matches(p) {
+			return p, true, nil
+		}
+	}
+	return models.Property{}, false, nil
+}
+
+func isJSONL(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".jsonl" || ext == ".ndjson"
+}
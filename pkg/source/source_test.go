@@ -0,0 +1,106 @@
+package source
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "listings.json")
+	jsonlPath := filepath.Join(dir, "listings.JSONL")
+	writeTestFile(t, jsonPath, "[]")
+	writeTestFile(t, jsonlPath, "")
+
+	tests := []struct {
+		name     string
+		location string
+		want     ListingSource
+		wantErr  bool
+	}{
+		{name: "json path", location: jsonPath, want: &File{Path: jsonPath}},
+		{name: "jsonl path", location: jsonlPath, want: &JSONL{Path: jsonlPath}},
+		{name: "directory", location: dir, want: &Dir{Path: dir}},
+		{name: "file URL", location: "file://" + jsonPath, want: &File{Path: jsonPath}},
+		{name: "http URL", location: "http://example.com/listings", want: NewHTTP("http://example.com/listings")},
+		{name: "https URL", location: "https://example.com/listings", want: NewHTTP("https://example.com/listings")},
+		{name: "missing file", location: filepath.Join(dir, "missing.json"), wantErr: true},
+		{name: "unsupported scheme", location: "ftp://example.com/listings.json", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Open(tt.location)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Open() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if h, ok := got.(*HTTP); ok {
+				h.Client = http.DefaultClient
+				tt.want.(*HTTP).Client = http.DefaultClient
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Open() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "listings.json")
+	writeTestFile(t, path, `[{"id":"1","systemId":"100","address":{"city":"Danbury"}},{"id":"2","address":{"city":"Bethel"}}]`)
+	src := &File{Path: path}
+
+	tests := []struct {
+		id     string
+		wantOK bool
+		want   string
+	}{
+		{id: "2", wantOK: true, want: "2"},
+		{id: "100", wantOK: true, want: "1"},
+		{id: "3", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			p, ok, err := Find(src, tt.id)
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if ok != tt.wantOK || p.ID != tt.want {
+				t.Errorf("Find() = %q, %v, want %q, %v", p.ID, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if _, _, err := Find(&File{Path: filepath.Join(t.TempDir(), "missing.json")}, "1"); err == nil {
+		t.Error("Find() on a missing file returned no error")
+	}
+
+	// the source is read up to the listing, the invalid data after it is not
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a.jsonl"), "{\"id\":\"1\"}\n{\"id\":\"2\"}\nnot a listing\n")
+	writeTestFile(t, filepath.Join(dir, "b.json"), "not a listing")
+	writeTestFile(t, filepath.Join(dir, "listings.csv"), "id,size\n1,2000\n2,a lot\n")
+	for _, src := range []ListingSource{&JSONL{Path: filepath.Join(dir, "a.jsonl")}, &Dir{Path: dir}, &CSV{Path: filepath.Join(dir, "listings.csv"), Mapping: DefaultCSVMapping()}} {
+		if p, ok, err := Find(src, "1"); err != nil || !ok || p.ID != "1" {
+			t.Errorf("Find(%T) = %q, %v, %v, want the listing before the invalid data", src, p.ID, ok, err)
+		}
+		if _, err := All(src); err == nil {
+			t.Errorf("All(%T) of the invalid data returned no error", src)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"math"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+const base32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// geohashPrecision is the number of characters of the geohash cells used by
+// the spatial index, a 5 character cell is about 4.9km x 4.9km
+const geohashPrecision = 5
+
+// encodeGeohash returns the geohash of a point with the given number of characters
+func encodeGeohash(lat, lon float64, precision int) string {
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+
+	hash := make([]byte, 0, precision)
+	bit, ch := 0, 0
+	even := true
+
+	for len(hash) < precision {
+		if even {
+			mid := (minLon + maxLon) / 2
+			if lon >= mid {
+				ch |= 1 << (4 - bit)
+				minLon = mid
+			} else {
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if lat >= mid {
+				ch |= 1 << (4 - bit)
+				minLat = mid
+			} else {
+				maxLat = mid
+			}
+		}
+		even = !even
+
+		if bit < 4 {
+			bit++
+		} else {
+			hash = append(hash, base32[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return string(hash)
+}
+
+// cellSize returns the height and width in degrees of a geohash cell
+func cellSize(precision int) (lat, lon float64) {
+	bits := precision * 5
+	lonBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 180 / math.Pow(2, float64(latBits)), 360 / math.Pow(2, float64(lonBits))
+}
+
+// coveringGeohashes returns the geohash cells intersecting the bounding box
+// of a circle around the point
+func coveringGeohashes(lat, lon, radiusMiles float64, precision int) []string {
+	dLat := radiusMiles / models.EarthRadiusMiles * 180 / math.Pi
+	dLon := dLat / math.Max(math.Cos(lat*math.Pi/180), 0.01)
+
+	cellLat, cellLon := cellSize(precision)
+
+	seen := map[string]bool{}
+	var hashes []string
+	for y := lat - dLat; ; y += cellLat {
+		y = math.Min(y, lat+dLat)
+		for x := lon - dLon; ; x += cellLon {
+			x = math.Min(x, lon+dLon)
+			hash := encodeGeohash(y, x, precision)
+			if !seen[hash] {
+				seen[hash] = true
+				hashes = append(hashes, hash)
+			}
+			if x >= lon+dLon {
+				break
+			}
+		}
+		if y >= lat+dLat {
+			break
+		}
+	}
+
+	return hashes
+}
@@ -0,0 +1,274 @@
+package store
+
+import (
+	"sort"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+// Query selects listings from a store, zero fields do not filter
+type Query struct {
+	City     string
+	Zip      string
+	Statuses []string
+
+	// MinSize and MaxSize bound the size in square feet (inclusive)
+	MinSize float64
+	MaxSize float64
+
+	// SoldAfter and SoldBefore bound the status change timestamp of closed
+	// listings (inclusive), other listings are excluded when they are set
+	SoldAfter  int64
+	SoldBefore int64
+
+	// Near and RadiusMiles select the listings within a distance of a point
+	Near        *models.Coordinates
+	RadiusMiles float64
+}
+
+// Store is an immutable in-memory index of listings. Every index holds
+// positions in the listings slice sorted by size so that size ranges are
+// found with a binary search.
+type Store struct {
+	listings []models.Property
+
+	all      []int
+	byCity   map[string][]int
+	byZip    map[string][]int
+	byStatus map[string][]int
+	byCell   map[string][]int
+
+	// closed holds the closed listings sorted by status change timestamp
+	closed []int
+}
+
+// New indexes the listings, the slice must not be modified afterwards
+func New(listings []models.Property) *Store {
+	s := &Store{
+		listings: listings,
+		byCity:   map[string][]int{},
+		byZip:    map[string][]int{},
+		byStatus: map[string][]int{},
+		byCell:   map[string][]int{},
+	}
+
+	s.all = make([]int, len(listings))
+	for i := range listings {
+		s.all[i] = i
+	}
+	s.sortBySize(s.all)
+
+	for _, i := range s.all {
+		p := listings[i]
+		city := address.CityBase(p.Address.City)
+		s.byCity[city] = append(s.byCity[city], i)
+		s.byZip[p.Address.Zip] = append(s.byZip[p.Address.Zip], i)
+		s.byStatus[p.Status] = append(s.byStatus[p.Status], i)
+		if hasCoordinates(p) {
+			cell := encodeGeohash(p.Coordinates.Latitude, p.Coordinates.Longitude, geohashPrecision)
+			s.byCell[cell] = append(s.byCell[cell], i)
+		}
+		if p.Status == "Closed" {
+			s.closed = append(s.closed, i)
+		}
+	}
+
+	sort.SliceStable(s.closed, func(a, b int) bool {
+		return listings[s.closed[a]].StatusChangeTimestamp < listings[s.closed[b]].StatusChangeTimestamp
+	})
+
+	return s
+}
+
+// Len returns the number of listings in the store
+func (s *Store) Len() int {
+	return len(s.listings)
+}
+
+// All returns every listing in the store
+func (s *Store) All() []models.Property {
+	return s.listings
+}
+
+// Query returns the listings matching every condition of the query, in the
+// order they were given to New
+func (s *Store) Query(q Query) []models.Property {
+	candidates := s.candidates(q)
+
+	var matches []int
+	for _, i := range candidates {
+		if q.Matches(s.listings[i]) {
+			matches = append(matches, i)
+		}
+	}
+	sort.Ints(matches)
+
+	result := make([]models.Property, 0, len(matches))
+	for j, i := range matches {
+		if j > 0 && matches[j-1] == i {
+			continue
+		}
+		result = append(result, s.listings[i])
+	}
+	return result
+}
+
+// candidates picks the smallest index usable by the query and narrows it to
+// the size range when the index is sorted by size. Indexes combining several
+// keys are only merged when they are picked.
+func (s *Store) candidates(q Query) []int {
+	type candidateList struct {
+		size   int
+		build  func() []int
+		bySize bool
+	}
+
+	var lists []candidateList
+	fixed := func(index []int, bySize bool) candidateList {
+		return candidateList{len(index), func() []int { return index }, bySize}
+	}
+	merged := func(keys []string, index map[string][]int) candidateList {
+		size := 0
+		for _, k := range keys {
+			size += len(index[k])
+		}
+		return candidateList{size, func() []int {
+			if len(keys) == 1 {
+				return index[keys[0]]
+			}
+			var result []int
+			for _, k := range keys {
+				result = append(result, index[k]...)
+			}
+			s.sortBySize(result)
+			return result
+		}, true}
+	}
+
+	if q.City != "" {
+		lists = append(lists, fixed(s.byCity[address.CityBase(q.City)], true))
+	}
+	if q.Zip != "" {
+		lists = append(lists, fixed(s.byZip[q.Zip], true))
+	}
+	if len(q.Statuses) > 0 {
+		lists = append(lists, merged(q.Statuses, s.byStatus))
+	}
+	if q.Near != nil && q.RadiusMiles > 0 {
+		cells := coveringGeohashes(q.Near.Latitude, q.Near.Longitude, q.RadiusMiles, geohashPrecision)
+		lists = append(lists, merged(cells, s.byCell))
+	}
+	if q.SoldAfter > 0 || q.SoldBefore > 0 {
+		lists = append(lists, fixed(s.soldBetween(q.SoldAfter, q.SoldBefore), false))
+	}
+
+	best := fixed(s.all, true)
+	for _, l := range lists {
+		if l.size < best.size {
+			best = l
+		}
+	}
+
+	index := best.build()
+	if !best.bySize {
+		return index
+	}
+	return s.sizeRange(index, q.MinSize, q.MaxSize)
+}
+
+func (s *Store) sortBySize(index []int) {
+	sort.SliceStable(index, func(a, b int) bool {
+		return s.listings[index[a]].Size < s.listings[index[b]].Size
+	})
+}
+
+// sizeRange returns the part of a size-sorted index within the bounds
+func (s *Store) sizeRange(index []int, minSize, maxSize float64) []int {
+	lo := 0
+	if minSize > 0 {
+		lo = sort.Search(len(index), func(i int) bool {
+			return s.listings[index[i]].Size >= minSize
+		})
+	}
+
+	hi := len(index)
+	if maxSize > 0 {
+		hi = sort.Search(len(index), func(i int) bool {
+			return s.listings[index[i]].Size > maxSize
+		})
+	}
+
+	if lo >= hi {
+		return nil
+	}
+	return index[lo:hi]
+}
+
+func (s *Store) soldBetween(after, before int64) []int {
+	lo := sort.Search(len(s.closed), func(i int) bool {
+		return s.listings[s.closed[i]].StatusChangeTimestamp >= after
+	})
+
+	hi := len(s.closed)
+	if before > 0 {
+		hi = sort.Search(len(s.closed), func(i int) bool {
+			return s.listings[s.closed[i]].StatusChangeTimestamp > before
+		})
+	}
+
+	if lo >= hi {
+		return nil
+	}
+	return s.closed[lo:hi]
+}
+
+// Matches reports whether a listing meets every condition of the query
+func (q Query) Matches(p models.Property) bool {
+	if q.City != "" && !address.SameCity(p.Address.City, q.City) {
+		return false
+	}
+	if q.Zip != "" && p.Address.Zip != q.Zip {
+		return false
+	}
+	if len(q.Statuses) > 0 && !contains(q.Statuses, p.Status) {
+		return false
+	}
+	if q.MinSize > 0 && p.Size < q.MinSize {
+		return false
+	}
+	if q.MaxSize > 0 && p.Size > q.MaxSize {
+		return false
+	}
+	if q.SoldAfter > 0 || q.SoldBefore > 0 {
+		if p.Status != "Closed" || p.StatusChangeTimestamp < q.SoldAfter {
+			return false
+		}
+		if q.SoldBefore > 0 && p.StatusChangeTimestamp > q.SoldBefore {
+			return false
+		}
+	}
+	if q.Near != nil && q.RadiusMiles > 0 {
+		if !hasCoordinates(p) {
+			return false
+		}
+		d := models.DistanceMiles(*q.Near, p.Coordinates)
+		if d > q.RadiusMiles {
+			return false
+		}
+	}
+	return true
+}
+
+func hasCoordinates(p models.Property) bool {
+	return p.Coordinates.Latitude != 0 || p.Coordinates.Longitude != 0
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/address"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+func createTestListings(n int, seed int64) []models.Property {
+	rng := rand.New(rand.NewSource(seed))
+	cities := []string{"Danbury", "Norwalk", "Stamford", "Bethel", "DANBURY CITY"}
+	zips := []string{"06810", "06811", "06850", "06901"}
+	statuses := []string{"Closed", "Active", "Under Contract"}
+
+	listings := make([]models.Property, n)
+	for i := range listings {
+		listings[i] = models.Property{
+			ID:      fmt.Sprint(i),
+			Address: models.Address{City: cities[rng.Intn(len(cities))], Zip: zips[rng.Intn(len(zips))]},
+			Coordinates: models.Coordinates{
+				Latitude:  41.0 + rng.Float64()*0.5,
+				Longitude: -73.7 + rng.Float64()*0.5,
+			},
+			Size:                  1000 + float64(rng.Intn(4000)),
+			Status:                statuses[rng.Intn(len(statuses))],
+			StatusChangeTimestamp: 1700000000 + int64(rng.Intn(30000000)),
+		}
+	}
+	return listings
+}
+
+// scan is the reference implementation every query is checked against
+func scan(listings []models.Property, q Query) []models.Property {
+	var result []models.Property
+	for _, p := range listings {
+		if q.Matches(p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func TestStore_Query(t *testing.T) {
+	address.SetDesignatedCities([]string{"Danbury"})
+	t.Cleanup(func() { address.SetDesignatedCities(nil) })
+
+	listings := createTestListings(2000, 1)
+	s := New(listings)
+
+	near := &models.Coordinates{Latitude: 41.25, Longitude: -73.45}
+
+	tests := []struct {
+		name  string
+		query Query
+	}{
+		{name: "everything", query: Query{}},
+		{name: "city", query: Query{City: "Danbury"}},
+		{name: "city and size", query: Query{City: "Danbury", MinSize: 2000, MaxSize: 2400}},
+		{name: "zip and status", query: Query{Zip: "06810", Statuses: []string{"Closed"}}},
+		{name: "several statuses", query: Query{Statuses: []string{"Active", "Under Contract"}, MinSize: 3000}},
+		{name: "duplicate statuses", query: Query{Statuses: []string{"Active", "Active"}}},
+		{name: "sale date window", query: Query{SoldAfter: 1710000000, SoldBefore: 1715000000}},
+		{name: "radius", query: Query{Near: near, RadiusMiles: 3}},
+		{name: "radius, city and size", query: Query{Near: near, RadiusMiles: 10, City: "Norwalk", MinSize: 1500, MaxSize: 2500}},
+		{name: "unknown city", query: Query{City: "Hartford"}},
+		{name: "city in another form", query: Query{City: "danbury city"}},
+		{name: "empty size range", query: Query{MinSize: 6000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.Query(tt.query)
+			want := scan(listings, tt.query)
+
+			if len(got) != len(want) {
+				t.Fatalf("Query() returned %d listings, want %d", len(got), len(want))
+			}
+			if len(want) > 0 && !reflect.DeepEqual(got, want) {
+				t.Error("Query() listings differ from a full scan")
+			}
+		})
+	}
+
+	if a, b := s.Query(Query{City: "Danbury"}), s.Query(Query{City: "danbury city"}); len(a) == 0 || len(a) != len(b) {
+		t.Errorf("Query() returned %d and %d listings for two forms of the same city", len(a), len(b))
+	}
+}
+
+func TestEncodeGeohash(t *testing.T) {
+	if got := encodeGeohash(57.64911, 10.40744, 11); got != "u4pruydqqvj" {
+		t.Errorf("encodeGeohash() = %v, want u4pruydqqvj", got)
+	}
+}
+
+func TestCoveringGeohashes(t *testing.T) {
+	listings := createTestListings(2000, 2)
+	near := models.Coordinates{Latitude: 41.2, Longitude: -73.5}
+
+	cells := map[string]bool{}
+	for _, c := range coveringGeohashes(near.Latitude, near.Longitude, 5, geohashPrecision) {
+		cells[c] = true
+	}
+
+	for _, p := range listings {
+		d := models.DistanceMiles(near, p.Coordinates)
+		cell := encodeGeohash(p.Coordinates.Latitude, p.Coordinates.Longitude, geohashPrecision)
+		if d <= 5 && !cells[cell] {
+			t.Fatalf("listing %s at %.2f miles is in cell %s which is not covered", p.ID, d, cell)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package tuning
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/backtest"
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+const (
+	defaultIterations = 50
+	defaultStep       = 0.1
+	minStep           = 0.005
+)
+
+// Tuner searches the weights and scores of a configuration using seeded
+// coordinate descent to minimize the median absolute percentage error of
+// a backtest over closed sales
+type Tuner struct {
+	Listings   []models.Property
+	Seed       int64
+	Iterations int
+	Step       float64
+}
+
+// Result holds the tuned configuration and the metrics before and after tuning
+type Result struct {
+	Config *config.Config   `json:"config"`
+	Before backtest.Metrics `json:"before"`
+	After  backtest.Metrics `json:"after"`
+}
+
+func NewTuner(listings []models.Property, seed int64) *Tuner {
+	return &Tuner{
+		Listings:   listings,
+		Seed:       seed,
+		Iterations: defaultIterations,
+		Step:       defaultStep,
+	}
+}
+
+// Tune returns a tuned copy of the given configuration, the original is not
+// modified. The top-level settings are tuned and every sale is valued with
+// them, no profile is selected, so to tune a profile pass the configuration
+// Config.Profile resolves for it. Candidates that are not valid, such as time
+// or status scores out of order, are skipped.
+func (t *Tuner) Tune(base *config.Config) Result {
+	rng := rand.New(rand.NewSource(t.Seed))
+
+	best := *base
+	best.Profiles = nil
+	bestMetrics := backtest.Run(&best, t.Listings).Metrics
+	before := bestMetrics
+
+	step := t.Step
+	for i := 0; i < t.Iterations && step >= minStep; i++ {
+		improved := false
+
+		for _, idx := range rng.Perm(len(parameters(&best))) {
+			for _, delta := range []float64{step, -step} {
+				candidate := best
+				params := parameters(&candidate)
+				*params[idx].value = round(clamp(*params[idx].value + delta))
+				normalizeWeights(&candidate)
+				if candidate.Validate() != nil {
+					continue
+				}
+
+				metrics := backtest.Run(&candidate, t.Listings).Metrics
+				if t.isBetter(metrics, bestMetrics, before.Count) {
+					best = candidate
+					bestMetrics = metrics
+					improved = true
+					break
+				}
+			}
+		}
+
+		if !improved {
+			step /= 2
+		}
+	}
+
+	best.Profiles = base.Profiles
+	return Result{
+		Config: &best,
+		Before: before,
+		After:  bestMetrics,
+	}
+}
+
+// isBetter compares two backtest runs, a candidate that values fewer sales
+// than the baseline is never better so the search cannot game the error
+// by discarding hard subjects
+func (t *Tuner) isBetter(candidate, current backtest.Metrics, minCount int) bool {
+	if candidate.Count == 0 || candidate.Count < minCount {
+		return false
+	}
+	return candidate.MdAPE < current.MdAPE-1e-9
+}
+
+type parameter struct {
+	value    *float64
+	isWeight bool
+}
+
+func parameters(cfg *config.Config) []parameter {
+	return []parameter{
+		{&cfg.CriteriaWeights.PropertyType, true},
+		{&cfg.CriteriaWeights.Bedrooms, true},
+		{&cfg.CriteriaWeights.Bathrooms, true},
+		{&cfg.CriteriaWeights.Size, true},
+		{&cfg.CriteriaWeights.Recency, true},
+		{&cfg.CriteriaWeights.Status, true},
+		{&cfg.CriteriaWeights.Neighborhood, true},
+		{&cfg.TimeScores.ThreeMonths, false},
+		{&cfg.TimeScores.SixMonths, false},
+		{&cfg.TimeScores.NineMonths, false},
+		{&cfg.StatusScores.Sold, false},
+		{&cfg.StatusScores.Pending, false},
+		{&cfg.StatusScores.Active, false},
+	}
+}
+
+// normalizeWeights rescales the criteria weights so they sum to 1
+func normalizeWeights(cfg *config.Config) {
+	var sum float64
+	for _, p := range parameters(cfg) {
+		if p.isWeight {
+			sum += *p.value
+		}
+	}
+	if sum == 0 {
+		return
+	}
+
+	for _, p := range parameters(cfg) {
+		if p.isWeight {
+			*p.value = round(*p.value / sum)
+		}
+	}
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
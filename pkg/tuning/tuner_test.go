@@ -0,0 +1,124 @@
+package tuning
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/krlosmederos/locqube-challenge/pkg/config"
+	"github.com/krlosmederos/locqube-challenge/pkg/models"
+)
+
+func createTestConfig() *config.Config {
+	cfg := &config.Config{MinSalesCount: 1}
+	cfg.CriteriaWeights.PropertyType = 0.2
+	cfg.CriteriaWeights.Bedrooms = 0.05
+	cfg.CriteriaWeights.Bathrooms = 0.05
+	cfg.CriteriaWeights.Size = 0.1
+	cfg.CriteriaWeights.Recency = 0.5
+	cfg.CriteriaWeights.Status = 0.1
+	cfg.TimeScores.ThreeMonths = 1.0
+	cfg.TimeScores.SixMonths = 0.5
+	cfg.TimeScores.NineMonths = 0.25
+	cfg.StatusScores.Sold = 1.0
+	cfg.StatusScores.Pending = 0.6
+	cfg.StatusScores.Active = 0.4
+	return cfg
+}
+
+func createTestListings() []models.Property {
+	now := time.Now().Unix()
+	month := int64(30 * 24 * 60 * 60)
+
+	listing := func(id, style string, price float64, age int64) models.Property {
+		return models.Property{
+			ID:                    id,
+			Address:               models.Address{City: "Danbury"},
+			Size:                  2000,
+			Beds:                  4,
+			Baths:                 models.Bathroom{Total: 2.5},
+			Style:                 style,
+			Status:                "Closed",
+			ListingDate:           now - age*month,
+			StatusChangeTimestamp: now - age*month,
+			ListPrice:             price,
+			SalePrice:             price,
+		}
+	}
+
+	return []models.Property{
+		listing("1", "Colonial", 600000, 1),
+		listing("2", "Colonial", 610000, 2),
+		listing("3", "Colonial", 590000, 5),
+		listing("4", "Ranch", 400000, 1),
+		listing("5", "Ranch", 410000, 2),
+		listing("6", "Ranch", 390000, 5),
+	}
+}
+
+func TestTuner_Tune(t *testing.T) {
+	base := createTestConfig()
+	original := *base
+
+	tuner := NewTuner(createTestListings(), 42)
+	result := tuner.Tune(base)
+
+	if !reflect.DeepEqual(*base, original) {
+		t.Error("Tune() modified the base configuration")
+	}
+
+	if result.After.MdAPE > result.Before.MdAPE {
+		t.Errorf("MdAPE after tuning = %v, want <= %v", result.After.MdAPE, result.Before.MdAPE)
+	}
+	if result.After.Count < result.Before.Count {
+		t.Errorf("Count after tuning = %v, want >= %v", result.After.Count, result.Before.Count)
+	}
+
+	if err := result.Config.Validate(); err != nil {
+		t.Errorf("Tune() returned an invalid configuration: %v", err)
+	}
+
+	w := result.Config.CriteriaWeights
+	sum := w.PropertyType + w.Bedrooms + w.Bathrooms + w.Size + w.Recency + w.Status + w.Neighborhood
+	if math.Abs(sum-1) > 0.001 {
+		t.Errorf("Tuned criteria weights sum to %v, want 1", sum)
+	}
+}
+
+func TestTuner_TuneIsDeterministic(t *testing.T) {
+	listings := createTestListings()
+
+	first := NewTuner(listings, 7).Tune(createTestConfig())
+	second := NewTuner(listings, 7).Tune(createTestConfig())
+
+	if !reflect.DeepEqual(first.Config, second.Config) {
+		t.Errorf("Tune() with the same seed produced different configurations: %+v vs %+v", *first.Config, *second.Config)
+	}
+}
+
+func TestTuner_TuneIgnoresProfiles(t *testing.T) {
+	listings := createTestListings()
+	want := NewTuner(listings, 3).Tune(createTestConfig())
+
+	// a profile matching every sale would otherwise replace the tuned weights
+	cfg := createTestConfig()
+	cfg.Profiles = map[string]config.Profile{
+		"danbury": {
+			Match:    config.ProfileMatch{City: "Danbury"},
+			Settings: json.RawMessage(`{"criteria_weights": {"property_type": 0, "bedrooms": 0, "bathrooms": 0, "size": 0, "recency": 1, "status": 0}}`),
+		},
+	}
+	got := NewTuner(listings, 3).Tune(cfg)
+
+	if got.Before != want.Before || got.After != want.After {
+		t.Errorf("Tune() with a matching profile = %+v -> %+v, want %+v -> %+v", got.Before, got.After, want.Before, want.After)
+	}
+	if got.Config.CriteriaWeights != want.Config.CriteriaWeights {
+		t.Errorf("Tune() with a matching profile tuned %+v, want %+v", got.Config.CriteriaWeights, want.Config.CriteriaWeights)
+	}
+	if _, ok := got.Config.Profiles["danbury"]; !ok {
+		t.Error("Tune() dropped the profiles of the configuration")
+	}
+}